@@ -1,13 +1,317 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
 	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 
 	g "github.com/mikowitz/goray/pkg"
+	"github.com/mikowitz/goray/pkg/distributed"
+	"github.com/mikowitz/goray/pkg/preview"
+	"github.com/mikowitz/goray/pkg/server"
 )
 
 func main() {
+	if len(os.Args) < 2 {
+		demo()
+		return
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "render":
+		err = render(os.Args[2:])
+	case "sequence":
+		err = sequence(os.Args[2:])
+	case "progressive":
+		err = progressive(os.Args[2:])
+	case "worker":
+		err = worker(os.Args[2:])
+	case "serve":
+		err = serve(os.Args[2:])
+	case "preview":
+		err = previewScene(os.Args[2:])
+	case "export":
+		err = export(os.Args[2:])
+	default:
+		fmt.Fprintln(os.Stderr, "usage: goray [render|sequence|progressive|worker|serve|preview|export] [flags] scene.json")
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func render(args []string) error {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	output := flags.String("o", "", "output file (.png or .ppm); PPM on stdout if empty")
+	frame := flags.Int("frame", 0, "animation frame to render")
+	aovDir := flags.String("aov", "", "directory to write depth, normal, albedo and object ID passes to")
+	denoise := flags.Bool("denoise", false, "denoise the image, guided by its normal and albedo passes")
+	checkpoint := flags.String("checkpoint", "", "file to save finished scanlines to and resume from")
+	checkpointEvery := flags.Duration("checkpoint-every", time.Minute, "how often to save the checkpoint")
+	workers := flags.String("workers", "", "comma separated worker URLs to spread the render across")
+	tileSize := flags.Int("tile", 32, "tile size in pixels when rendering on workers")
+	post := postFlags(flags)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("render: expected one scene file")
+	}
+
+	scene, err := g.LoadSceneFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := post(&scene.Post); err != nil {
+		return err
+	}
+	if *workers != "" {
+		if *checkpoint != "" || *aovDir != "" || *denoise {
+			return fmt.Errorf("render: -workers cannot be combined with -checkpoint, -aov or -denoise")
+		}
+		document, err := os.ReadFile(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		co := distributed.NewCoordinator(strings.Split(*workers, ",")...)
+		co.TileSize = *tileSize
+		canvas, err := co.Render(context.Background(), document, *frame)
+		if err != nil {
+			return err
+		}
+		return writeCanvas(canvas.PostProcess(scene.Post), *output)
+	}
+	if *checkpoint != "" {
+		if *aovDir != "" || *denoise {
+			return fmt.Errorf("render: -checkpoint cannot be combined with -aov or -denoise")
+		}
+		w, c := scene.Frame(*frame)
+		canvas, err := c.RenderCheckpointed(w, *checkpoint, *checkpointEvery)
+		if err != nil {
+			return err
+		}
+		return writeCanvas(canvas.PostProcess(scene.Post), *output)
+	}
+	if *aovDir == "" && !*denoise {
+		return writeCanvas(scene.RenderFrame(*frame), *output)
+	}
+
+	w, c := scene.Frame(*frame)
+	canvas, aovs := c.RenderWithAOVs(w)
+	if *aovDir != "" {
+		if err := aovs.Save(*aovDir); err != nil {
+			return err
+		}
+	}
+	if *denoise {
+		canvas = g.NewDenoiser().Apply(canvas, &aovs)
+	}
+	return writeCanvas(canvas.PostProcess(scene.Post), *output)
+}
+
+func sequence(args []string) error {
+	flags := flag.NewFlagSet("sequence", flag.ExitOnError)
+	first := flags.Int("first", -1, "first frame (defaults to the scene's)")
+	last := flags.Int("last", -1, "last frame (defaults to the scene's)")
+	dir := flags.String("dir", "frames", "directory to write frames to")
+	post := postFlags(flags)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("sequence: expected one scene file")
+	}
+
+	scene, err := g.LoadSceneFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := post(&scene.Post); err != nil {
+		return err
+	}
+	if *first < 0 {
+		*first = scene.FirstFrame
+	}
+	if *last < 0 {
+		*last = scene.LastFrame
+	}
+	return scene.RenderSequence(*first, *last, *dir)
+}
+
+func progressive(args []string) error {
+	flags := flag.NewFlagSet("progressive", flag.ExitOnError)
+	output := flags.String("o", "progressive.png", "output file (.png or .ppm)")
+	frame := flags.Int("frame", 0, "animation frame to render")
+	passes := flags.Int("passes", 64, "number of passes to add, each one sample per pixel")
+	every := flags.Int("every", 8, "write the image and state after this many passes")
+	state := flags.String("state", "", "accumulation buffer to resume from and save to")
+	post := postFlags(flags)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("progressive: expected one scene file")
+	}
+
+	scene, err := g.LoadSceneFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := post(&scene.Post); err != nil {
+		return err
+	}
+	w, c := scene.Frame(*frame)
+
+	acc := g.NewAccumulator(w, c)
+	if *state != "" {
+		saved, err := g.LoadAccumulatorFile(*state)
+		switch {
+		case err == nil && saved.SceneHash != acc.SceneHash:
+			return fmt.Errorf("progressive: %s: %w", *state, g.ErrSceneChanged)
+		case err == nil:
+			acc = saved
+		case !errors.Is(err, fs.ErrNotExist):
+			return err
+		}
+	}
+
+	save := func(image g.Canvas) error {
+		if *state != "" {
+			if err := acc.SaveFile(*state); err != nil {
+				return err
+			}
+		}
+		return writeCanvas(image.PostProcess(scene.Post), *output)
+	}
+
+	var snapshotErr error
+	err = c.RenderProgressive(w, &acc, *passes, func(pass int, image g.Canvas) {
+		if snapshotErr == nil && *every > 0 && pass%*every == 0 {
+			snapshotErr = save(image)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if snapshotErr != nil {
+		return snapshotErr
+	}
+	return save(acc.Image())
+}
+
+func worker(args []string) error {
+	flags := flag.NewFlagSet("worker", flag.ExitOnError)
+	listen := flags.String("listen", ":8070", "address to serve tile requests on")
+	allowFiles := flags.Bool("allow-files", false, "let scenes read environment maps from the worker's filesystem")
+	flags.Parse(args)
+
+	wk := distributed.NewWorker()
+	wk.AllowFiles = *allowFiles
+	fmt.Fprintf(os.Stderr, "goray worker listening on %s\n", *listen)
+	return http.ListenAndServe(*listen, wk)
+}
+
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := flags.String("listen", ":8080", "address to serve the render API on")
+	concurrency := flags.Int("concurrency", runtime.NumCPU(), "number of jobs to render at once")
+	flags.Parse(args)
+
+	s := server.NewServer(*concurrency)
+	defer s.Close()
+	fmt.Fprintf(os.Stderr, "goray serving on %s\n", *listen)
+	return http.ListenAndServe(*listen, s)
+}
+
+func previewScene(args []string) error {
+	flags := flag.NewFlagSet("preview", flag.ExitOnError)
+	listen := flags.String("listen", "localhost:8000", "address to serve the preview on")
+	frame := flags.Int("frame", 0, "animation frame to render")
+	tileSize := flags.Int("tile", 16, "tile size in pixels")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("preview: expected one scene file")
+	}
+
+	p := preview.NewPreview(flags.Arg(0))
+	p.Frame = *frame
+	p.TileSize = *tileSize
+	go p.Run(context.Background())
+
+	fmt.Fprintf(os.Stderr, "goray preview of %s on http://%s/\n", flags.Arg(0), *listen)
+	return http.ListenAndServe(*listen, p)
+}
+
+// export writes the built-in demo scene as a scene document, as a starting
+// point for new scenes.
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("o", "", "scene file to write; stdout if empty")
+	flags.Parse(args)
+
+	scene := demoScene()
+	if *output == "" {
+		return g.SaveScene(os.Stdout, scene)
+	}
+	return g.SaveSceneFile(*output, scene)
+}
+
+// postFlags registers the post-processing flags on flags. The returned
+// function overrides the scene's settings with any of them that were given.
+func postFlags(flags *flag.FlagSet) func(*g.PostProcess) error {
+	exposure := flags.Float64("exposure", 0, "exposure adjustment in stops")
+	toneMap := flags.String("tonemap", "clamp", "tone map to apply (clamp, reinhard or aces)")
+	srgb := flags.Bool("srgb", false, "encode the output with the sRGB transfer function")
+
+	return func(p *g.PostProcess) error {
+		var err error
+		flags.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "exposure":
+				p.Exposure = *exposure
+			case "tonemap":
+				p.ToneMap, err = g.ParseToneMap(*toneMap)
+			case "srgb":
+				p.SRGB = *srgb
+			}
+		})
+		return err
+	}
+}
+
+func writeCanvas(canvas g.Canvas, path string) error {
+	if path == "" {
+		_, err := fmt.Println(canvas.ToPpm())
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if filepath.Ext(path) == ".png" {
+		return canvas.WritePNG(f)
+	}
+	_, err = fmt.Fprint(f, canvas.ToPpm())
+	return err
+}
+
+func demo() {
+	scene := demoScene()
+	canvas := scene.Camera.Render(scene.World)
+
+	fmt.Println(canvas.ToPpm())
+}
+
+func demoScene() g.Scene {
 	// floorPattern := g.NewSolidPattern(g.NewColor(0.9, 0.9, 0.9))
 	floorPattern := g.NewCheckersPattern(g.NewColor(0, 0, 0), g.NewColor(1, 1, 1))
 	floor := g.NewPlane()
@@ -20,11 +324,11 @@ func main() {
 	wallPattern.SetTransform(g.Scaling(0.33, 0.33, 0.33))
 
 	wall := g.NewPlane()
-	wall.SetTransform(g.Translation(0, 0, 10).Mul(g.RotationY(math.Pi / 3)).Mul(g.RotationZ(math.Pi / 2)).Mul(g.RotationX(math.Pi / 2)))
+	wall.SetTransform(g.NewTransformBuilder().RotateX(math.Pi/2).RotateZ(math.Pi/2).RotateY(math.Pi/3).Translate(0, 0, 10).Matrix())
 	wall.Material.Pattern = &wallPattern
 
 	otherWall := g.NewPlane()
-	otherWall.SetTransform(g.Translation(0, 0, 10).Mul(g.RotationY(-math.Pi / 3)).Mul(g.RotationZ(math.Pi / 2)).Mul(g.RotationX(math.Pi / 2)))
+	otherWall.SetTransform(g.NewTransformBuilder().RotateX(math.Pi/2).RotateZ(math.Pi/2).RotateY(-math.Pi/3).Translate(0, 0, 10).Matrix())
 	otherWall.Material.Pattern = &wallPattern
 
 	red := g.NewSolidPattern(g.NewColor(1, 0, 0.5))
@@ -46,7 +350,5 @@ func main() {
 		g.NewVector(0, 1, 0),
 	)
 
-	canvas := c.Render(world)
-
-	fmt.Println(canvas.ToPpm())
+	return g.Scene{World: world, Camera: c}
 }
@@ -0,0 +1,213 @@
+package goray
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+type Easing int
+
+const (
+	Linear Easing = iota
+	EaseIn
+	EaseOut
+	EaseInOut
+)
+
+func (e Easing) Apply(t float64) float64 {
+	switch e {
+	case EaseIn:
+		return t * t
+	case EaseOut:
+		return t * (2.0 - t)
+	case EaseInOut:
+		return t * t * (3.0 - 2.0*t)
+	default:
+		return t
+	}
+}
+
+// Keyframe pins a value to a frame. Its Easing shapes the interpolation
+// between it and the next keyframe.
+type Keyframe[T any] struct {
+	Frame  float64
+	Value  T
+	Easing Easing
+}
+
+type Track[T any] struct {
+	Keys        []Keyframe[T]
+	Interpolate func(a, b T, t float64) T
+}
+
+func NewTrack[T any](interpolate func(a, b T, t float64) T, keys ...Keyframe[T]) Track[T] {
+	keys = slices.Clone(keys)
+	slices.SortStableFunc(keys, func(a, b Keyframe[T]) int {
+		return cmp.Compare(a.Frame, b.Frame)
+	})
+	return Track[T]{Keys: keys, Interpolate: interpolate}
+}
+
+// NewMatrixTrack animates transforms with Matrix.Slerp, so a shape spun
+// between keyframes turns rather than shrinking through the middle.
+func NewMatrixTrack(keys ...Keyframe[Matrix]) Track[Matrix] {
+	return NewTrack(Matrix.Slerp, keys...)
+}
+
+// NewViewTrack animates view transforms such as those NewViewTransform
+// builds. It moves the camera itself rather than the world in front of it,
+// so a camera turning on the spot stays where it is between keyframes.
+func NewViewTrack(keys ...Keyframe[Matrix]) Track[Matrix] {
+	return NewTrack(func(a, b Matrix, t float64) Matrix {
+		if t == 0 {
+			return a
+		}
+		if t == 1 {
+			return b
+		}
+		placeA, okA := a.TryInverse()
+		placeB, okB := b.TryInverse()
+		if okA && okB {
+			if view, ok := placeA.Slerp(placeB, t).TryInverse(); ok {
+				return view
+			}
+		}
+		return a.Lerp(b, t)
+	}, keys...)
+}
+
+func NewPointTrack(keys ...Keyframe[Point]) Track[Point] {
+	return NewTrack(Point.Lerp, keys...)
+}
+
+func NewFloatTrack(keys ...Keyframe[float64]) Track[float64] {
+	return NewTrack(func(a, b, t float64) float64 {
+		return a + (b-a)*t
+	}, keys...)
+}
+
+func (tr Track[T]) At(frame float64) T {
+	if len(tr.Keys) == 0 {
+		var zero T
+		return zero
+	}
+	if frame <= tr.Keys[0].Frame {
+		return tr.Keys[0].Value
+	}
+	last := tr.Keys[len(tr.Keys)-1]
+	if frame >= last.Frame {
+		return last.Value
+	}
+
+	i := slices.IndexFunc(tr.Keys, func(k Keyframe[T]) bool {
+		return k.Frame > frame
+	})
+	a, b := tr.Keys[i-1], tr.Keys[i]
+	t := (frame - a.Frame) / (b.Frame - a.Frame)
+	return tr.Interpolate(a.Value, b.Value, a.Easing.Apply(math.Max(0.0, math.Min(1.0, t))))
+}
+
+type Animator interface {
+	Animate(frame float64, w *World, c *Camera)
+}
+
+type Animation []Animator
+
+func (a Animation) Apply(frame float64, w *World, c *Camera) {
+	for _, animator := range a {
+		animator.Animate(frame, w, c)
+	}
+}
+
+type CameraTransformTrack struct {
+	Track Track[Matrix]
+}
+
+func (ct CameraTransformTrack) Animate(frame float64, _ *World, c *Camera) {
+	c.Transform = ct.Track.At(frame)
+}
+
+type ShapeTransformTrack struct {
+	Shape Shape
+	Track Track[Matrix]
+}
+
+func (st ShapeTransformTrack) Animate(frame float64, _ *World, _ *Camera) {
+	st.Shape.SetTransform(st.Track.At(frame))
+}
+
+func (st ShapeTransformTrack) target() Shape {
+	return st.Shape
+}
+
+func (st ShapeTransformTrack) retarget(shape Shape) Animator {
+	st.Shape = shape
+	return st
+}
+
+type LightPositionTrack struct {
+	Track Track[Point]
+}
+
+func (lt LightPositionTrack) Animate(frame float64, w *World, _ *Camera) {
+	w.LightSource.Position = lt.Track.At(frame)
+}
+
+type MaterialProperty int
+
+const (
+	AmbientProperty MaterialProperty = iota
+	DiffuseProperty
+	SpecularProperty
+	ShininessProperty
+	ReflectiveProperty
+	TransparencyProperty
+	RefractiveIndexProperty
+	MetallicProperty
+	RoughnessProperty
+)
+
+type MaterialTrack struct {
+	Shape    Shape
+	Property MaterialProperty
+	Track    Track[float64]
+}
+
+func (mt MaterialTrack) Animate(frame float64, _ *World, _ *Camera) {
+	m := mt.Shape.GetMaterial()
+	*m.property(mt.Property) = mt.Track.At(frame)
+	mt.Shape.SetMaterial(m)
+}
+
+func (mt MaterialTrack) target() Shape {
+	return mt.Shape
+}
+
+func (mt MaterialTrack) retarget(shape Shape) Animator {
+	mt.Shape = shape
+	return mt
+}
+
+func (m *Material) property(p MaterialProperty) *float64 {
+	switch p {
+	case AmbientProperty:
+		return &m.Ambient
+	case DiffuseProperty:
+		return &m.Diffuse
+	case SpecularProperty:
+		return &m.Specular
+	case ShininessProperty:
+		return &m.Shininess
+	case ReflectiveProperty:
+		return &m.Reflective
+	case TransparencyProperty:
+		return &m.Transparency
+	case RefractiveIndexProperty:
+		return &m.RefractiveIndex
+	case MetallicProperty:
+		return &m.Metallic
+	default:
+		return &m.Roughness
+	}
+}
@@ -0,0 +1,131 @@
+package goray
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEasing(t *testing.T) {
+	for _, e := range []Easing{Linear, EaseIn, EaseOut, EaseInOut} {
+		assert.Equal(t, e.Apply(0), 0.0)
+		assert.Equal(t, e.Apply(1), 1.0)
+	}
+
+	assert.Equal(t, Linear.Apply(0.25), 0.25)
+	assert.Equal(t, EaseIn.Apply(0.5), 0.25)
+	assert.Equal(t, EaseOut.Apply(0.5), 0.75)
+	assert.Equal(t, EaseInOut.Apply(0.5), 0.5)
+	assert.Less(t, EaseInOut.Apply(0.25), 0.25)
+}
+
+func TestTrack(t *testing.T) {
+	track := NewFloatTrack(
+		Keyframe[float64]{Frame: 10, Value: 1},
+		Keyframe[float64]{Frame: 0, Value: 0, Easing: EaseIn},
+		Keyframe[float64]{Frame: 20, Value: 3},
+	)
+
+	t.Run("sorts its keyframes", func(t *testing.T) {
+		assert.Equal(t, track.Keys[0].Frame, 0.0)
+		assert.Equal(t, track.Keys[2].Frame, 20.0)
+	})
+
+	t.Run("holds the first value before the first key", func(t *testing.T) {
+		assert.Equal(t, track.At(-5), 0.0)
+	})
+
+	t.Run("holds the last value after the last key", func(t *testing.T) {
+		assert.Equal(t, track.At(25), 3.0)
+	})
+
+	t.Run("eases between keys", func(t *testing.T) {
+		assert.Equal(t, track.At(5), 0.25)
+	})
+
+	t.Run("interpolates linearly between keys", func(t *testing.T) {
+		assert.Equal(t, track.At(15), 2.0)
+	})
+
+	t.Run("hits keys exactly", func(t *testing.T) {
+		assert.Equal(t, track.At(10), 1.0)
+	})
+}
+
+func TestAnimation(t *testing.T) {
+	w := defaultWorld()
+	c := NewCamera(11, 1, 1)
+
+	animation := Animation{
+		CameraTransformTrack{Track: NewMatrixTrack(
+			Keyframe[Matrix]{Frame: 0, Value: Translation(0, 0, 0)},
+			Keyframe[Matrix]{Frame: 10, Value: Translation(0, 0, 10)},
+		)},
+		ShapeTransformTrack{Shape: w.Objects[0], Track: NewMatrixTrack(
+			Keyframe[Matrix]{Frame: 0, Value: Translation(0, 0, 0)},
+			Keyframe[Matrix]{Frame: 10, Value: Translation(10, 0, 0)},
+		)},
+		LightPositionTrack{Track: NewPointTrack(
+			Keyframe[Point]{Frame: 0, Value: NewPoint(0, 0, 0)},
+			Keyframe[Point]{Frame: 10, Value: NewPoint(0, 10, 0)},
+		)},
+		MaterialTrack{Shape: w.Objects[1], Property: ReflectiveProperty, Track: NewFloatTrack(
+			Keyframe[float64]{Frame: 0, Value: 0},
+			Keyframe[float64]{Frame: 10, Value: 1},
+		)},
+	}
+
+	animation.Apply(5, &w, &c)
+
+	assert.True(t, MatricesEqual(c.Transform, Translation(0, 0, 5)))
+	assert.True(t, MatricesEqual(w.Objects[0].GetTransform(), Translation(5, 0, 0)))
+	assert.True(t, TuplesEqual(w.LightSource.Position, NewPoint(0, 5, 0)))
+	assert.Equal(t, w.Objects[1].GetMaterial().Reflective, 0.5)
+}
+
+func TestTransformTracks(t *testing.T) {
+	t.Run("matrix tracks turn without shrinking", func(t *testing.T) {
+		track := NewMatrixTrack(
+			Keyframe[Matrix]{Frame: 0, Value: IdentityMatrix()},
+			Keyframe[Matrix]{Frame: 10, Value: RotationY(math.Pi / 2)},
+		)
+		assert.True(t, MatricesEqual(track.At(5), RotationY(math.Pi/4)))
+	})
+
+	t.Run("matrix tracks survive a half turn", func(t *testing.T) {
+		track := NewMatrixTrack(
+			Keyframe[Matrix]{Frame: 0, Value: IdentityMatrix()},
+			Keyframe[Matrix]{Frame: 10, Value: RotationY(math.Pi)},
+		)
+		for frame := range 11 {
+			assert.True(t, track.At(float64(frame)).IsInvertible())
+		}
+	})
+
+	t.Run("view tracks survive a half turn", func(t *testing.T) {
+		from := NewPoint(0, 1, 0)
+		track := NewViewTrack(
+			Keyframe[Matrix]{Frame: 0, Value: NewViewTransform(from, NewPoint(0, 1, 1), NewVector(0, 1, 0))},
+			Keyframe[Matrix]{Frame: 10, Value: NewViewTransform(from, NewPoint(0, 1, -1), NewVector(0, 1, 0))},
+		)
+		c := NewCamera(11, 1, 1)
+		for frame := range 11 {
+			c.Transform = track.At(float64(frame))
+			assert.NotPanics(t, func() { c.RayForPixel(5, 5) })
+		}
+	})
+
+	t.Run("view tracks keep a camera turning on the spot in place", func(t *testing.T) {
+		from := NewPoint(0, 1, -5)
+		track := NewViewTrack(
+			Keyframe[Matrix]{Frame: 0, Value: NewViewTransform(from, NewPoint(0, 1, 0), NewVector(0, 1, 0))},
+			Keyframe[Matrix]{Frame: 10, Value: NewViewTransform(from, NewPoint(5, 1, -5), NewVector(0, 1, 0))},
+		)
+
+		halfway := track.At(5)
+		expected := NewViewTransform(from, NewPoint(1, 1, -4), NewVector(0, 1, 0))
+		assert.True(t, MatricesEqual(halfway, expected))
+		assert.True(t, TuplesEqual(halfway.Inverse().MulPoint(NewPoint(0, 0, 0)), from))
+	})
+}
@@ -0,0 +1,158 @@
+package goray
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// AOVs holds the auxiliary passes rendered alongside the beauty image. Each
+// pass records what the first camera ray traced through a pixel hits: the
+// distance to it, its world space normal, its unlit pattern color and its
+// object ID, which is its index in the world's Objects plus one. Pixels whose
+// ray misses keep zeroes everywhere.
+type AOVs struct {
+	Depth, Normal, Albedo, ObjectID Canvas
+}
+
+func NewAOVs(width, height int) AOVs {
+	return AOVs{
+		Depth:    blankCanvas(width, height),
+		Normal:   blankCanvas(width, height),
+		Albedo:   blankCanvas(width, height),
+		ObjectID: blankCanvas(width, height),
+	}
+}
+
+// firstHit catches what a camera ray hits as the integrator shades it, so
+// the AOVs cost no extra intersections. Only the first ray traced through a
+// pixel is kept.
+type firstHit struct {
+	traced bool
+	isHit  bool
+	ray    Ray
+	hit    Intersection
+	comps  Computations
+}
+
+// record keeps the hit, or the miss when hit is nil, unless a ray has
+// already been recorded. It does nothing on a nil firstHit.
+func (f *firstHit) record(r Ray, hit *Intersection, comps Computations) {
+	if f == nil || f.traced {
+		return
+	}
+	f.traced = true
+	if hit != nil {
+		f.isHit, f.ray, f.hit, f.comps = true, r, *hit, comps
+	}
+}
+
+func (a AOVs) record(objects []Shape, f firstHit, x, y int) {
+	if !f.isHit {
+		return
+	}
+	material := f.comps.Object.GetMaterial()
+	depth := f.hit.T * f.ray.Direction.Magnitude()
+	id := float64(slices.Index(objects, f.hit.Object) + 1)
+
+	a.Depth.Write(x, y, NewColor(depth, depth, depth))
+	a.Normal.Write(x, y, Color(f.comps.Normalv))
+	a.Albedo.Write(x, y, PatternAtObject(material.Pattern, f.comps.Object, f.comps.Point))
+	a.ObjectID.Write(x, y, NewColor(id, id, id))
+}
+
+func (a AOVs) DepthImage() Canvas {
+	far := 0.0
+	for _, p := range a.Depth.Pixels {
+		far = math.Max(far, p.x)
+	}
+	image := blankCanvas(a.Depth.Width, a.Depth.Height)
+	if far == 0.0 {
+		return image
+	}
+	for i, p := range a.Depth.Pixels {
+		if p.x > 0.0 {
+			v := 1.0 - p.x/far
+			image.Pixels[i] = NewColor(v, v, v)
+		}
+	}
+	return image
+}
+
+func (a AOVs) NormalImage() Canvas {
+	image := blankCanvas(a.Normal.Width, a.Normal.Height)
+	for i, n := range a.Normal.Pixels {
+		if n != (Color{}) {
+			image.Pixels[i] = NewColor(0.5*n.x+0.5, 0.5*n.y+0.5, 0.5*n.z+0.5)
+		}
+	}
+	return image
+}
+
+func (a AOVs) ObjectIDImage() Canvas {
+	image := blankCanvas(a.ObjectID.Width, a.ObjectID.Height)
+	for i, p := range a.ObjectID.Pixels {
+		if p.x > 0.0 {
+			image.Pixels[i] = idColor(int(p.x))
+		}
+	}
+	return image
+}
+
+func idColor(id int) Color {
+	h := math.Mod(float64(id)*0.618033988749895, 1.0) * 6.0
+	f := h - math.Floor(h)
+	switch int(h) {
+	case 0:
+		return NewColor(1, f, 0)
+	case 1:
+		return NewColor(1-f, 1, 0)
+	case 2:
+		return NewColor(0, 1, f)
+	case 3:
+		return NewColor(0, 1-f, 1)
+	case 4:
+		return NewColor(f, 0, 1)
+	default:
+		return NewColor(1, 0, 1-f)
+	}
+}
+
+// Save writes every pass into dir twice: as a float .pfm holding the raw
+// values and as a .png preview scaled for viewing.
+func (a AOVs) Save(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	passes := []struct {
+		name         string
+		data, viewer Canvas
+	}{
+		{"depth", a.Depth, a.DepthImage()},
+		{"normal", a.Normal, a.NormalImage()},
+		{"albedo", a.Albedo, a.Albedo},
+		{"object_id", a.ObjectID, a.ObjectIDImage()},
+	}
+	for _, p := range passes {
+		if err := writePFMFile(p.data, filepath.Join(dir, p.name+".pfm")); err != nil {
+			return err
+		}
+		if err := writeFrame(p.viewer, filepath.Join(dir, p.name+".png")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePFMFile(c Canvas, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := c.WritePFM(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,112 @@
+package goray
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderWithAOVs(t *testing.T) {
+	w := defaultWorld()
+	c := NewCamera(11, 1, math.Pi/2)
+	c.Transform = NewViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	image, aovs := c.RenderWithAOVs(w)
+
+	t.Run("renders the same beauty image", func(t *testing.T) {
+		assert.Equal(t, image.Pixels, c.Render(w).Pixels)
+	})
+
+	t.Run("records the depth of the first hit", func(t *testing.T) {
+		assert.True(t, TuplesEqual(aovs.Depth.At(5, 5), NewColor(4, 4, 4)))
+	})
+
+	t.Run("records the world space normal", func(t *testing.T) {
+		assert.True(t, TuplesEqual(aovs.Normal.At(5, 5), NewColor(0, 0, -1)))
+	})
+
+	t.Run("records the unlit surface color", func(t *testing.T) {
+		assert.True(t, TuplesEqual(aovs.Albedo.At(5, 5), NewColor(0.8, 1, 0.6)))
+	})
+
+	t.Run("records the object ID", func(t *testing.T) {
+		assert.True(t, TuplesEqual(aovs.ObjectID.At(5, 5), NewColor(1, 1, 1)))
+	})
+
+	t.Run("leaves pixels that miss empty", func(t *testing.T) {
+		assert.Equal(t, aovs.Depth.At(0, 0), Color{})
+		assert.Equal(t, aovs.Normal.At(0, 0), Color{})
+		assert.Equal(t, aovs.ObjectID.At(0, 0), Color{})
+	})
+}
+
+// countingSphere counts the rays intersected with it.
+type countingSphere struct {
+	Sphere
+	rays int
+}
+
+func (s *countingSphere) LocalIntersect(r Ray) Intersections {
+	s.rays++
+	return s.Sphere.LocalIntersect(r)
+}
+
+func TestRenderWithAOVsTracesNoExtraRays(t *testing.T) {
+	w := defaultWorld()
+	s := &countingSphere{Sphere: *w.Objects[0].(*Sphere)}
+	w.Objects[0] = s
+	c := NewCamera(11, 1, math.Pi/2)
+	c.Transform = NewViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	c.Render(w)
+	rays := s.rays
+	s.rays = 0
+	c.RenderWithAOVs(w)
+	assert.Equal(t, s.rays, rays)
+}
+
+func TestRenderWithAOVsAndManySamples(t *testing.T) {
+	w := defaultWorld()
+	c := NewCamera(11, 1, math.Pi/2)
+	c.Transform = NewViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+	c.Integrator = NewPathTracer()
+	c.SamplesPerPixel = 4
+
+	image, aovs := c.RenderWithAOVs(w)
+	assert.Equal(t, image.Pixels, c.Render(w).Pixels)
+	assert.InDelta(t, aovs.Depth.At(5, 5).x, 4.0, 0.15)
+	assert.True(t, TuplesEqual(aovs.ObjectID.At(5, 5), NewColor(1, 1, 1)))
+	assert.Equal(t, aovs.Depth.At(0, 0), Color{})
+}
+
+func TestAOVImages(t *testing.T) {
+	aovs := NewAOVs(2, 1)
+	aovs.Depth.Write(0, 0, NewColor(2, 2, 2))
+	aovs.Depth.Write(1, 0, NewColor(4, 4, 4))
+	aovs.Normal.Write(0, 0, NewColor(0, 0, -1))
+	aovs.ObjectID.Write(1, 0, NewColor(2, 2, 2))
+
+	assert.True(t, TuplesEqual(aovs.DepthImage().At(0, 0), NewColor(0.5, 0.5, 0.5)))
+	assert.True(t, TuplesEqual(aovs.DepthImage().At(1, 0), Black()))
+	assert.True(t, TuplesEqual(aovs.NormalImage().At(0, 0), NewColor(0.5, 0.5, 0)))
+	assert.Equal(t, aovs.NormalImage().At(1, 0), Color{})
+	assert.Equal(t, aovs.ObjectIDImage().At(0, 0), Color{})
+	assert.NotEqual(t, aovs.ObjectIDImage().At(1, 0), Color{})
+}
+
+func TestSavingAOVs(t *testing.T) {
+	dir := t.TempDir()
+	aovs := NewAOVs(2, 2)
+
+	assert.NoError(t, aovs.Save(dir))
+
+	for _, name := range []string{"depth", "normal", "albedo", "object_id"} {
+		for _, ext := range []string{".pfm", ".png"} {
+			_, err := os.Stat(filepath.Join(dir, name+ext))
+			assert.NoError(t, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package goray
+
+import "math"
+
+type Background interface {
+	At(direction Vector) Color
+}
+
+type ConstantBackground struct {
+	Color Color
+}
+
+func NewConstantBackground(c Color) ConstantBackground {
+	return ConstantBackground{Color: c}
+}
+
+func (cb ConstantBackground) At(_ Vector) Color {
+	return cb.Color
+}
+
+type GradientBackground struct {
+	Bottom, Top Color
+}
+
+func NewGradientBackground(bottom, top Color) GradientBackground {
+	return GradientBackground{Bottom: bottom, Top: top}
+}
+
+func (gb GradientBackground) At(direction Vector) Color {
+	t := 0.5 * (direction.Normalize().y + 1.0)
+	return gb.Bottom.Add(gb.Top.Sub(gb.Bottom).Mul(t))
+}
+
+// SkyBackground is a simple analytic daylight sky: the zenith colour fades
+// into the horizon colour towards the horizon, directions below it see the
+// ground, and the sun adds a bright disc and a glow around it.
+type SkyBackground struct {
+	SunDirection                     Vector
+	SunColor                         Color
+	SunAngularRadius                 float64
+	ZenithColor, HorizonColor        Color
+	GroundColor                      Color
+	GlowIntensity, GlowConcentration float64
+}
+
+func NewSkyBackground(sunDirection Vector) SkyBackground {
+	return SkyBackground{
+		SunDirection:      sunDirection.Normalize(),
+		SunColor:          NewColor(20, 18, 15),
+		SunAngularRadius:  0.0047,
+		ZenithColor:       NewColor(0.25, 0.45, 0.85),
+		HorizonColor:      NewColor(0.85, 0.9, 1.0),
+		GroundColor:       NewColor(0.3, 0.28, 0.25),
+		GlowIntensity:     0.5,
+		GlowConcentration: 32.0,
+	}
+}
+
+func (sb SkyBackground) At(direction Vector) Color {
+	direction = direction.Normalize()
+	if direction.y < 0.0 {
+		return sb.GroundColor
+	}
+
+	t := math.Pow(1.0-direction.y, 4)
+	sky := sb.ZenithColor.Add(sb.HorizonColor.Sub(sb.ZenithColor).Mul(t))
+
+	cosSun := direction.Dot(sb.SunDirection)
+	if cosSun >= math.Cos(sb.SunAngularRadius) {
+		return sky.Add(sb.SunColor)
+	}
+	if cosSun > 0.0 {
+		glow := sb.GlowIntensity * math.Pow(cosSun, sb.GlowConcentration)
+		// The glow takes the sun's hue but not its brightness.
+		hue := Tuple(sb.SunColor).Normalize()
+		sky = sky.Add(Color(hue).Mul(glow))
+	}
+	return sky
+}
+
+// EnvironmentMap looks directions up in an equirectangular (latitude /
+// longitude) image, such as one loaded with LoadHDR. The top row of the image
+// is straight up, and its horizontal centre faces -z. Rotation spins the map
+// about the y axis. Path is the file the image was loaded from, if any, and
+// is what SaveScene writes in place of the image.
+type EnvironmentMap struct {
+	Image     Canvas
+	Intensity float64
+	Rotation  float64
+	Path      string
+}
+
+func NewEnvironmentMap(image Canvas) EnvironmentMap {
+	return EnvironmentMap{Image: image, Intensity: 1.0}
+}
+
+func (em EnvironmentMap) At(direction Vector) Color {
+	direction = direction.Normalize()
+	phi := math.Atan2(direction.x, -direction.z) + em.Rotation
+	theta := math.Acos(math.Max(-1.0, math.Min(1.0, direction.y)))
+
+	u := 0.5 + phi/(2.0*math.Pi)
+	u -= math.Floor(u)
+	v := theta / math.Pi
+
+	return em.sample(u, v).Mul(em.Intensity)
+}
+
+func (em EnvironmentMap) sample(u, v float64) Color {
+	x := u*float64(em.Image.Width) - 0.5
+	y := math.Min(math.Max(v*float64(em.Image.Height)-0.5, 0.0), float64(em.Image.Height-1))
+
+	x0 := int(math.Floor(x))
+	y0 := int(math.Floor(y))
+	fx := x - float64(x0)
+	fy := y - float64(y0)
+
+	wrap := func(x int) int {
+		return ((x % em.Image.Width) + em.Image.Width) % em.Image.Width
+	}
+	y1 := min(y0+1, em.Image.Height-1)
+
+	top := em.Image.At(wrap(x0), y0).Mul(1 - fx).Add(em.Image.At(wrap(x0+1), y0).Mul(fx))
+	bottom := em.Image.At(wrap(x0), y1).Mul(1 - fx).Add(em.Image.At(wrap(x0+1), y1).Mul(fx))
+	return top.Mul(1 - fy).Add(bottom.Mul(fy))
+}
@@ -0,0 +1,169 @@
+package goray
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstantBackground(t *testing.T) {
+	b := NewConstantBackground(NewColor(0.2, 0.3, 0.4))
+
+	assert.Equal(t, b.At(NewVector(0, 1, 0)), NewColor(0.2, 0.3, 0.4))
+	assert.Equal(t, b.At(NewVector(1, -1, 0)), NewColor(0.2, 0.3, 0.4))
+}
+
+func TestGradientBackground(t *testing.T) {
+	b := NewGradientBackground(Black(), White())
+
+	assert.True(t, TuplesEqual(b.At(NewVector(0, 1, 0)), White()))
+	assert.True(t, TuplesEqual(b.At(NewVector(0, -1, 0)), Black()))
+	assert.True(t, TuplesEqual(b.At(NewVector(0, 0, 1)), NewColor(0.5, 0.5, 0.5)))
+}
+
+func TestSkyBackground(t *testing.T) {
+	sun := NewVector(0, 1, -1)
+	b := NewSkyBackground(sun)
+
+	t.Run("looking at the sun", func(t *testing.T) {
+		c := b.At(sun)
+		assert.Greater(t, c.x, 10.0)
+	})
+
+	t.Run("looking straight up", func(t *testing.T) {
+		c := b.At(NewVector(0, 1, 0))
+		assert.Less(t, c.x, c.z)
+	})
+
+	t.Run("looking below the horizon", func(t *testing.T) {
+		assert.Equal(t, b.At(NewVector(0, -1, 0)), b.GroundColor)
+	})
+}
+
+func TestEnvironmentMap(t *testing.T) {
+	image := NewCanvas(4, 2)
+	for x := range 4 {
+		image.Write(x, 0, NewColor(1, 0, 0))
+		image.Write(x, 1, NewColor(0, 0, 1))
+	}
+	m := NewEnvironmentMap(image)
+
+	t.Run("looking up sees the top of the image", func(t *testing.T) {
+		assert.True(t, TuplesEqual(m.At(NewVector(0, 1, 0)), NewColor(1, 0, 0)))
+	})
+
+	t.Run("looking down sees the bottom of the image", func(t *testing.T) {
+		assert.True(t, TuplesEqual(m.At(NewVector(0, -1, 0)), NewColor(0, 0, 1)))
+	})
+
+	t.Run("scales by its intensity", func(t *testing.T) {
+		m := m
+		m.Intensity = 2.0
+		assert.True(t, TuplesEqual(m.At(NewVector(0, 1, 0)), NewColor(2, 0, 0)))
+	})
+
+	t.Run("wraps around horizontally", func(t *testing.T) {
+		image := NewCanvas(4, 4)
+		image.Write(0, 0, NewColor(1, 0, 0))
+		image.Write(3, 0, NewColor(0, 1, 0))
+		m := NewEnvironmentMap(image)
+
+		c := m.At(NewVector(0, 0, 1))
+		assert.True(t, TuplesEqual(c, NewColor(0.5, 0.5, 0)))
+	})
+}
+
+func TestWorldBackground(t *testing.T) {
+	t.Run("colors rays that miss", func(t *testing.T) {
+		w := defaultWorld()
+		w.Background = NewConstantBackground(NewColor(0.5, 0.6, 0.7))
+
+		c := w.ColorAt(NewRay(NewPoint(0, 0, -5), NewVector(0, 1, 0)), 0)
+
+		assert.True(t, TuplesEqual(c, NewColor(0.5, 0.6, 0.7)))
+	})
+
+	t.Run("is seen in reflections", func(t *testing.T) {
+		w := NewWorld()
+		w.Background = NewConstantBackground(NewColor(0.5, 0.6, 0.7))
+		mirror := NewPlane()
+		mirror.Material.Reflective = 1.0
+		mirror.Material.Ambient = 0.0
+		mirror.Material.Diffuse = 0.0
+		mirror.Material.Specular = 0.0
+		w.Objects = []Shape{&mirror}
+
+		r := NewRay(NewPoint(0, 1, -1), NewVector(0, -math.Sqrt2/2, math.Sqrt2/2))
+		c := w.ColorAt(r, 2)
+
+		assert.True(t, TuplesEqual(c, NewColor(0.5, 0.6, 0.7)))
+	})
+
+	t.Run("lights the scene when image based lighting is enabled", func(t *testing.T) {
+		w := NewWorld()
+		w.Background = NewConstantBackground(White())
+		w.IBLSamples = 16
+		floor := NewPlane()
+		floor.Material.Ambient = 0.0
+		w.Objects = []Shape{&floor}
+
+		r := NewRay(NewPoint(0, 1, 0), NewVector(0, -1, 0))
+		c := w.ColorAt(r, 0)
+
+		assert.True(t, TuplesEqual(c, NewColor(0.9, 0.9, 0.9)))
+	})
+}
+
+func TestHDRRoundTrip(t *testing.T) {
+	c := NewCanvas(3, 1)
+	c.Write(0, 0, NewColor(1, 0.5, 0.25))
+	c.Write(1, 1, NewColor(12.5, 3, 0))
+	c.Write(2, 2, NewColor(0.001, 0.002, 0.003))
+
+	var buf bytes.Buffer
+	assert.NoError(t, c.WriteHDR(&buf))
+
+	d, err := LoadHDR(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, d.Width, 3)
+	assert.Equal(t, d.Height, 3)
+	for i, p := range c.Pixels {
+		q := d.Pixels[i]
+		ε := math.Max(p.x, math.Max(p.y, p.z)) / 128
+		assert.InDelta(t, p.x, q.x, ε)
+		assert.InDelta(t, p.y, q.y, ε)
+		assert.InDelta(t, p.z, q.z, ε)
+	}
+}
+
+func TestLoadRunLengthEncodedHDR(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y 1 +X 8\n")
+	buf.Write([]byte{2, 2, 0, 8})
+	buf.Write([]byte{128 + 8, 128})
+	buf.Write([]byte{8, 0, 16, 32, 48, 64, 80, 96, 112})
+	buf.Write([]byte{128 + 8, 0})
+	buf.Write([]byte{128 + 8, 129})
+
+	c, err := LoadHDR(&buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, c.Width, 8)
+	for x := range 8 {
+		assert.True(t, TuplesEqual(c.At(x, 0), NewColor(1, float64(x)/8, 0)))
+	}
+}
+
+func TestLoadHDRRejectsBadSizes(t *testing.T) {
+	for _, resolution := range []string{"-Y 0 +X 8", "-Y -1 +X 8", "-Y 1 +X -8", "-Y 100000 +X 100000", "-Y 65536 +X 65536"} {
+		_, err := LoadHDR(bytes.NewBufferString("#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n" + resolution + "\n"))
+		assert.ErrorContains(t, err, "image size", resolution)
+	}
+}
+
+func TestLoadHDRRejectsOtherFormats(t *testing.T) {
+	_, err := LoadHDR(bytes.NewBufferString("P3\n1 1\n255\n0 0 0\n"))
+	assert.Error(t, err)
+}
@@ -1,7 +1,9 @@
 package goray
 
 import (
+	"context"
 	"math"
+	"math/rand/v2"
 	"os"
 
 	"github.com/schollz/progressbar/v3"
@@ -12,6 +14,11 @@ type Camera struct {
 	AspectRatio           float64
 	FieldOfView           float64
 	Transform             Matrix
+	Integrator            Integrator
+	SamplesPerPixel       int
+	Seed                  uint64
+	ShutterOpen           float64
+	ShutterClose          float64
 	halfWidth, halfHeight float64
 	pixelSize             float64
 }
@@ -31,48 +38,149 @@ func NewCamera(width int, aspectRatio, fieldOfView float64) Camera {
 	}
 
 	return Camera{
-		Width:       width,
-		Height:      height,
-		AspectRatio: aspectRatio,
-		FieldOfView: fieldOfView,
-		Transform:   IdentityMatrix(),
-		halfWidth:   halfWidth,
-		halfHeight:  halfHeight,
-		pixelSize:   (halfWidth * 2) / float64(width),
+		Width:           width,
+		Height:          height,
+		AspectRatio:     aspectRatio,
+		FieldOfView:     fieldOfView,
+		Transform:       IdentityMatrix(),
+		Integrator:      NewWhittedIntegrator(),
+		SamplesPerPixel: 1,
+		halfWidth:       halfWidth,
+		halfHeight:      halfHeight,
+		pixelSize:       (halfWidth * 2) / float64(width),
 	}
 }
 
 func (c Camera) RayForPixel(x, y int) Ray {
-	xOffset := (float64(x) + 0.5) * c.pixelSize
-	yOffset := (float64(y) + 0.5) * c.pixelSize
+	return c.RayForPixelOffset(x, y, 0.5, 0.5)
+}
+
+func (c Camera) RayForPixelOffset(x, y int, dx, dy float64) Ray {
+	xOffset := (float64(x) + dx) * c.pixelSize
+	yOffset := (float64(y) + dy) * c.pixelSize
 
 	worldX := c.halfWidth - xOffset
 	worldY := c.halfHeight - yOffset
 
-	pixel := c.Transform.Inverse().Mult(NewPoint(worldX, worldY, -1))
-	origin := c.Transform.Inverse().Mult(NewPoint(0, 0, 0))
+	pixel := c.Transform.Inverse().MulPoint(NewPoint(worldX, worldY, -1))
+	origin := c.Transform.Inverse().MulPoint(NewPoint(0, 0, 0))
 	direction := pixel.Sub(origin).Normalize()
 
 	return NewRay(origin, direction)
 }
 
 func (c Camera) Render(w World) Canvas {
-	canvas := NewCanvas(c.Width, c.AspectRatio)
+	canvas, _ := c.render(context.Background(), w, nil, c.progressBar())
+	return canvas
+}
+
+func (c Camera) RenderWithAOVs(w World) (Canvas, AOVs) {
+	aovs := NewAOVs(c.Width, c.Height)
+	canvas, _ := c.render(context.Background(), w, &aovs, c.progressBar())
+	return canvas, aovs
+}
+
+// RenderContext renders like Render but reports its progress to progress, if
+// it is not nil, instead of drawing a progress bar. It is called after every
+// scanline with the number of pixels finished and the total. Between
+// scanlines the render checks ctx and gives up with its error once it is
+// done.
+func (c Camera) RenderContext(ctx context.Context, w World, progress func(done, total int)) (Canvas, error) {
+	return c.render(ctx, w, nil, progress)
+}
 
+func (c Camera) progressBar() func(done, total int) {
 	bar := progressbar.NewOptions(c.Width*c.Height,
 		progressbar.OptionSetWriter(os.Stderr),
 	)
+	return func(done, _ int) {
+		if err := bar.Set(done); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (c Camera) render(ctx context.Context, w World, aovs *AOVs, progress func(done, total int)) (Canvas, error) {
+	canvas := NewCanvas(c.Width, c.AspectRatio)
+	w = w.prepared()
 
 	for y := range c.Height {
+		if err := ctx.Err(); err != nil {
+			return Canvas{}, err
+		}
 		for x := range c.Width {
-			ray := c.RayForPixel(x, y)
-			color := w.ColorAt(ray, 5)
-			canvas.Write(x, y, color)
-			err := bar.Add(1)
-			if err != nil {
-				panic(err)
+			rng := c.pixelRand(x, y)
+			pixel := w
+			if pixel.Rand == nil {
+				pixel.Rand = rng
+			}
+			if aovs == nil {
+				canvas.Write(x, y, c.ColorForPixel(pixel, x, y, rng))
+				continue
 			}
+			var first firstHit
+			pixel.first = &first
+			canvas.Write(x, y, c.ColorForPixel(pixel, x, y, rng))
+			aovs.record(w.Objects, first, x, y)
+		}
+		if progress != nil {
+			progress((y+1)*c.Width, c.Width*c.Height)
 		}
 	}
-	return canvas
+	return canvas, nil
+}
+
+// RenderTile renders the width by height block of pixels whose top left
+// corner is (x, y). Pixels draw from the same random streams as they do in
+// Render, so an image assembled from tiles is the same however it was split
+// up, and the same as one rendered whole.
+func (c Camera) RenderTile(w World, x, y, width, height int) Canvas {
+	tile := blankCanvas(width, height)
+	w = w.prepared()
+	for ty := range height {
+		for tx := range width {
+			px, py := x+tx, y+ty
+			rng := c.pixelRand(px, py)
+			w.Rand = rng
+			tile.Write(tx, ty, c.ColorForPixel(w, px, py, rng))
+		}
+	}
+	return tile
+}
+
+// pixelRand is the random stream the pixel at (x, y) is rendered with,
+// seeded by the camera's Seed and the pixel's position.
+func (c Camera) pixelRand(x, y int) *rand.Rand {
+	return rand.New(rand.NewPCG(c.Seed, uint64(y*c.Width+x)+1))
+}
+
+func (c Camera) ColorForPixel(w World, x, y int, rng *rand.Rand) Color {
+	integrator := c.Integrator
+	if integrator == nil {
+		integrator = NewWhittedIntegrator()
+	}
+
+	if c.SamplesPerPixel <= 1 {
+		return integrator.Li(w, c.primaryRay(x, y), rng)
+	}
+
+	color := Black()
+	for range c.SamplesPerPixel {
+		color = color.Add(integrator.Li(w, c.sampleRay(x, y, rng), rng))
+	}
+	return color.Div(float64(c.SamplesPerPixel))
+}
+
+// sampleRay returns a ray through a random point of the pixel at a random
+// time while the shutter is open.
+func (c Camera) sampleRay(x, y int, rng *rand.Rand) Ray {
+	ray := c.RayForPixelOffset(x, y, rng.Float64(), rng.Float64())
+	ray.Time = c.ShutterOpen + rng.Float64()*(c.ShutterClose-c.ShutterOpen)
+	return ray
+}
+
+func (c Camera) primaryRay(x, y int) Ray {
+	ray := c.RayForPixel(x, y)
+	ray.Time = c.ShutterOpen
+	return ray
 }
@@ -1,6 +1,7 @@
 package goray
 
 import (
+	"context"
 	"math"
 	"testing"
 
@@ -14,6 +15,8 @@ func TestConstructingACamera(t *testing.T) {
 	assert.Equal(t, c.Height, 120)
 	assert.Equal(t, c.FieldOfView, math.Pi/2)
 	assert.True(t, MatricesEqual(c.Transform, IdentityMatrix()))
+	assert.Equal(t, c.Integrator, NewWhittedIntegrator())
+	assert.Equal(t, c.SamplesPerPixel, 1)
 }
 
 func TestPixelSize(t *testing.T) {
@@ -69,3 +72,89 @@ func TestRenderingAWorldWithACamera(t *testing.T) {
 
 	assert.True(t, TuplesEqual(image.At(5, 5), NewColor(0.38066, 0.47583, 0.2855)))
 }
+
+func TestRenderingWithMultipleSamples(t *testing.T) {
+	w := defaultWorld()
+	c := NewCamera(11, 1, math.Pi/2)
+	c.Transform = NewViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+	c.Integrator = NewPathTracer()
+	c.SamplesPerPixel = 4
+	c.Seed = 7
+
+	a := c.Render(w)
+	b := c.Render(w)
+
+	assert.Equal(t, a.Pixels, b.Pixels)
+}
+
+func TestRenderingWithMotionBlur(t *testing.T) {
+	w := NewWorld()
+	w.LightSource = NewPointLight(NewPoint(0, 0, -10), White())
+	s := NewSphere()
+	s.Material.Ambient = 1.0
+	s.Material.Diffuse = 0.0
+	s.Material.Specular = 0.0
+	s.SetTransform(Translation(-1.5, 0, 0).Mul(Scaling(0.5, 0.5, 0.5)))
+	s.SetEndTransform(Translation(1.5, 0, 0).Mul(Scaling(0.5, 0.5, 0.5)))
+	w.Objects = []Shape{&s}
+
+	c := NewCamera(11, 1, math.Pi/2)
+	c.Transform = NewViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+	c.SamplesPerPixel = 64
+	c.ShutterOpen = 0.0
+	c.ShutterClose = 1.0
+
+	image := c.Render(w)
+	center := image.At(5, 5)
+
+	assert.Greater(t, center.x, 0.0)
+	assert.Less(t, center.x, 1.0)
+}
+
+func TestRenderTile(t *testing.T) {
+	w := defaultWorld()
+	c := NewCamera(6, 1.5, math.Pi/2)
+	c.Transform = NewViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+	c.Integrator = NewPathTracer()
+	c.SamplesPerPixel = 2
+
+	whole := c.RenderTile(w, 0, 0, c.Width, c.Height)
+
+	assembled := NewCanvas(c.Width, c.AspectRatio)
+	for _, tile := range [][4]int{{0, 0, 4, 3}, {4, 0, 2, 3}, {0, 3, 6, 1}} {
+		assembled.Paste(c.RenderTile(w, tile[0], tile[1], tile[2], tile[3]), tile[0], tile[1])
+	}
+	assert.Equal(t, assembled, whole)
+	assert.Equal(t, c.Render(w), whole)
+}
+
+func TestRenderContext(t *testing.T) {
+	w := defaultWorld()
+	c := NewCamera(11, 1, math.Pi/2)
+	c.Transform = NewViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	t.Run("renders the same image as Render", func(t *testing.T) {
+		var reports []int
+		image, err := c.RenderContext(context.Background(), w, func(done, total int) {
+			assert.Equal(t, total, 121)
+			reports = append(reports, done)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, image, c.Render(w))
+		assert.Len(t, reports, 11)
+		assert.Equal(t, reports[10], 121)
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		rows := 0
+		_, err := c.RenderContext(ctx, w, func(done, _ int) {
+			rows++
+			if rows == 3 {
+				cancel()
+			}
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, rows, 3)
+	})
+}
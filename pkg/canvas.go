@@ -2,6 +2,10 @@ package goray
 
 import (
 	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
 	"strings"
 )
 
@@ -15,10 +19,33 @@ func NewCanvas(width int, aspectRatio float64) Canvas {
 	return Canvas{
 		Width:  width,
 		Height: height,
-		Pixels: make([]Tuple, width*height),
+		Pixels: make([]Color, width*height),
 	}
 }
 
+func blankCanvas(width, height int) Canvas {
+	return Canvas{Width: width, Height: height, Pixels: make([]Color, width*height)}
+}
+
+// Images read from files are bounded, so a corrupt or hostile header cannot
+// ask for more memory than any real image needs.
+const (
+	maxImageSide   = 1 << 16
+	maxImagePixels = 1 << 25
+)
+
+// checkImageSize reports whether a width by height image is one it is
+// reasonable to allocate.
+func checkImageSize(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("image size %dx%d is not positive", width, height)
+	}
+	if width > maxImageSide || height > maxImageSide || width*height > maxImagePixels {
+		return fmt.Errorf("image size %dx%d is too large", width, height)
+	}
+	return nil
+}
+
 func (c Canvas) Write(x, y int, color Color) {
 	c.Pixels[y*c.Width+x] = color
 }
@@ -27,6 +54,13 @@ func (c Canvas) At(x, y int) Color {
 	return c.Pixels[y*c.Width+x]
 }
 
+// Paste copies src into c with its top left corner at (x, y).
+func (c Canvas) Paste(src Canvas, x, y int) {
+	for sy := range src.Height {
+		copy(c.Pixels[(y+sy)*c.Width+x:], src.Pixels[sy*src.Width:(sy+1)*src.Width])
+	}
+}
+
 func (c Canvas) ToPpm() string {
 	pixels := make([]string, c.Height*c.Width)
 
@@ -36,3 +70,32 @@ func (c Canvas) ToPpm() string {
 
 	return fmt.Sprintf("P3\n%d %d\n255\n%s\n", c.Width, c.Height, strings.Join(pixels, "\n"))
 }
+
+func (c Canvas) ToImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, c.Width, c.Height))
+	for y := range c.Height {
+		for x := range c.Width {
+			r, g, b := c.At(x, y).ToBytes()
+			img.SetNRGBA(x, y, color.NRGBA{R: r, G: g, B: b, A: 255})
+		}
+	}
+	return img
+}
+
+// CanvasFromImage converts an 8-bit image such as a decoded PNG back into
+// linear [0, 1] colors, ignoring alpha.
+func CanvasFromImage(img image.Image) Canvas {
+	bounds := img.Bounds()
+	c := blankCanvas(bounds.Dx(), bounds.Dy())
+	for y := range c.Height {
+		for x := range c.Width {
+			p := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			c.Write(x, y, NewColor(float64(p.R)/255.0, float64(p.G)/255.0, float64(p.B)/255.0))
+		}
+	}
+	return c
+}
+
+func (c Canvas) WritePNG(w io.Writer) error {
+	return png.Encode(w, c.ToImage())
+}
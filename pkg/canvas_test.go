@@ -1,6 +1,7 @@
 package goray
 
 import (
+	"image/color"
 	"strings"
 	"testing"
 
@@ -57,3 +58,30 @@ func TestConstructingPPMPixelData(t *testing.T) {
 		}
 	}
 }
+
+func TestCanvasToImage(t *testing.T) {
+	c := NewCanvas(2, 2)
+	c.Write(0, 0, NewColor(1.5, 0, 0))
+	c.Write(1, 0, NewColor(0, 0.5, 0))
+
+	img := c.ToImage()
+
+	assert.Equal(t, img.Bounds().Dx(), 2)
+	assert.Equal(t, img.Bounds().Dy(), 1)
+	assert.Equal(t, img.NRGBAAt(0, 0), color.NRGBA{R: 255, G: 0, B: 0, A: 255})
+	assert.Equal(t, img.NRGBAAt(1, 0), color.NRGBA{R: 0, G: 128, B: 0, A: 255})
+}
+
+func TestPastingACanvas(t *testing.T) {
+	c := blankCanvas(4, 3)
+	tile := blankCanvas(2, 2)
+	tile.Write(0, 0, NewColor(1, 0, 0))
+	tile.Write(1, 1, NewColor(0, 1, 0))
+
+	c.Paste(tile, 2, 1)
+
+	assert.Equal(t, c.At(2, 1), NewColor(1, 0, 0))
+	assert.Equal(t, c.At(3, 2), NewColor(0, 1, 0))
+	assert.Equal(t, c.At(1, 1), Color{})
+	assert.Equal(t, c.At(3, 0), Color{})
+}
@@ -0,0 +1,193 @@
+package goray
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+var ErrSceneChanged = errors.New("render was saved from a different scene or camera")
+
+// Checkpoint is a partly rendered image. Done records which scanlines of
+// Canvas are finished.
+type Checkpoint struct {
+	SceneHash [32]byte
+	Canvas    Canvas
+	Done      []bool
+}
+
+func NewCheckpoint(w World, c Camera) Checkpoint {
+	return Checkpoint{
+		SceneHash: HashScene(w, c),
+		Canvas:    blankCanvas(c.Width, c.Height),
+		Done:      make([]bool, c.Height),
+	}
+}
+
+var checkpointMagic = [4]byte{'G', 'C', 'K', 'P'}
+
+type checkpointHeader struct {
+	Magic         [4]byte
+	SceneHash     [32]byte
+	Width, Height uint32
+}
+
+// Save writes a header holding the scene hash and image size, one byte per
+// scanline marking it done, and then the little-endian float64 RGB values of
+// every pixel.
+func (cp Checkpoint) Save(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	header := checkpointHeader{checkpointMagic, cp.SceneHash, uint32(cp.Canvas.Width), uint32(cp.Canvas.Height)}
+	if err := binary.Write(bw, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	if err := binary.Write(bw, binary.LittleEndian, cp.Done); err != nil {
+		return err
+	}
+	for _, p := range cp.Canvas.Pixels {
+		if err := binary.Write(bw, binary.LittleEndian, [3]float64{p.x, p.y, p.z}); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func LoadCheckpoint(r io.Reader) (Checkpoint, error) {
+	return loadCheckpoint(r, -1)
+}
+
+// loadCheckpoint reads a checkpoint from r, which holds size bytes, or an
+// unknown number when size is negative. The size is checked against the
+// header before anything it describes is allocated.
+func loadCheckpoint(r io.Reader, size int64) (Checkpoint, error) {
+	br := bufio.NewReader(r)
+	var header checkpointHeader
+	if err := binary.Read(br, binary.LittleEndian, &header); err != nil {
+		return Checkpoint{}, err
+	}
+	if header.Magic != checkpointMagic {
+		return Checkpoint{}, errors.New("checkpoint: bad signature")
+	}
+	width, height := int(header.Width), int(header.Height)
+	if err := checkImageSize(width, height); err != nil {
+		return Checkpoint{}, fmt.Errorf("checkpoint: %w", err)
+	}
+	if want := int64(binary.Size(header) + height + width*height*24); size >= 0 && size != want {
+		return Checkpoint{}, fmt.Errorf("checkpoint: %d bytes is the wrong size for a %dx%d image", size, width, height)
+	}
+
+	cp := Checkpoint{
+		SceneHash: header.SceneHash,
+		Canvas:    blankCanvas(width, height),
+		Done:      make([]bool, height),
+	}
+	if err := binary.Read(br, binary.LittleEndian, cp.Done); err != nil {
+		return Checkpoint{}, err
+	}
+	for i := range cp.Canvas.Pixels {
+		var rgb [3]float64
+		if err := binary.Read(br, binary.LittleEndian, &rgb); err != nil {
+			return Checkpoint{}, err
+		}
+		cp.Canvas.Pixels[i] = NewColor(rgb[0], rgb[1], rgb[2])
+	}
+	return cp, nil
+}
+
+// SaveFile writes the checkpoint beside path and then renames it into place,
+// so a render killed mid-save still leaves the previous checkpoint intact.
+func (cp Checkpoint) SaveFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if err := cp.Save(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func LoadCheckpointFile(path string) (Checkpoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	return loadCheckpoint(f, info.Size())
+}
+
+// RenderCheckpointed renders like Render but saves finished scanlines to
+// path whenever at least every has passed since the last save, and once more
+// at the end. If path already holds a checkpoint for the same world and
+// camera, the scanlines it has finished are kept and only the rest are
+// rendered; a checkpoint from anything else returns ErrSceneChanged. Pixels
+// draw from the same random streams as they do in Render, so a resumed render
+// matches one that was never interrupted.
+func (c Camera) RenderCheckpointed(w World, path string, every time.Duration) (Canvas, error) {
+	cp, err := LoadCheckpointFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		cp = NewCheckpoint(w, c)
+	case err != nil:
+		return Canvas{}, err
+	case cp.SceneHash != HashScene(w, c):
+		return Canvas{}, ErrSceneChanged
+	}
+
+	remaining := 0
+	for _, done := range cp.Done {
+		if !done {
+			remaining++
+		}
+	}
+	bar := progressbar.NewOptions(remaining*c.Width,
+		progressbar.OptionSetWriter(os.Stderr),
+	)
+
+	w = w.prepared()
+	lastSave := time.Now()
+	for y := range c.Height {
+		if cp.Done[y] {
+			continue
+		}
+		for x := range c.Width {
+			rng := c.pixelRand(x, y)
+			w.Rand = rng
+			cp.Canvas.Write(x, y, c.ColorForPixel(w, x, y, rng))
+		}
+		cp.Done[y] = true
+		if err := bar.Add(c.Width); err != nil {
+			return Canvas{}, err
+		}
+
+		if time.Since(lastSave) >= every {
+			if err := cp.SaveFile(path); err != nil {
+				return Canvas{}, err
+			}
+			lastSave = time.Now()
+		}
+	}
+
+	if err := cp.SaveFile(path); err != nil {
+		return Canvas{}, err
+	}
+	return cp.Canvas, nil
+}
@@ -0,0 +1,115 @@
+package goray
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func checkpointScene() (World, Camera) {
+	w := defaultWorld()
+	c := NewCamera(6, 1.5, math.Pi/2)
+	c.Transform = NewViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+	c.SamplesPerPixel = 2
+	c.Seed = 5
+	return w, c
+}
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	w, c := checkpointScene()
+	cp := NewCheckpoint(w, c)
+	cp.Done[1] = true
+	cp.Canvas.Write(2, 1, NewColor(0.25, 1.5, 0))
+
+	var buf bytes.Buffer
+	assert.NoError(t, cp.Save(&buf))
+	loaded, err := LoadCheckpoint(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, loaded, cp)
+
+	_, err = LoadCheckpoint(bytes.NewReader([]byte("GACC0000000000000000")))
+	assert.Error(t, err)
+}
+
+func TestLoadCheckpointRejectsBadSizes(t *testing.T) {
+	header := func(width, height uint32) []byte {
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, checkpointHeader{Magic: checkpointMagic, Width: width, Height: height})
+		return buf.Bytes()
+	}
+
+	t.Run("that are empty or huge", func(t *testing.T) {
+		for _, size := range [][2]uint32{{0, 4}, {4, 0}, {math.MaxUint32, 1}, {1 << 16, 1 << 16}} {
+			_, err := LoadCheckpoint(bytes.NewReader(header(size[0], size[1])))
+			assert.ErrorContains(t, err, "image size")
+		}
+	})
+
+	t.Run("that do not match the file", func(t *testing.T) {
+		w, c := checkpointScene()
+		path := filepath.Join(t.TempDir(), "render.ckpt")
+		assert.NoError(t, NewCheckpoint(w, c).SaveFile(path))
+		_, err := LoadCheckpointFile(path)
+		assert.NoError(t, err)
+
+		assert.NoError(t, os.WriteFile(path, header(4000, 4000), 0o644))
+		_, err = LoadCheckpointFile(path)
+		assert.ErrorContains(t, err, "wrong size")
+	})
+}
+
+func TestRenderCheckpointed(t *testing.T) {
+	t.Run("renders the same image as Render without a checkpoint", func(t *testing.T) {
+		w, c := checkpointScene()
+		c.SamplesPerPixel = 1
+		path := filepath.Join(t.TempDir(), "render.ckpt")
+
+		image, err := c.RenderCheckpointed(w, path, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, image, c.Render(w))
+
+		cp, err := LoadCheckpointFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, cp.Done, []bool{true, true, true, true})
+	})
+
+	t.Run("resumes only the unfinished scanlines", func(t *testing.T) {
+		w, c := checkpointScene()
+		path := filepath.Join(t.TempDir(), "render.ckpt")
+		full, err := c.RenderCheckpointed(w, path, 0)
+		assert.NoError(t, err)
+
+		cp, err := LoadCheckpointFile(path)
+		assert.NoError(t, err)
+		cp.Done[2], cp.Done[3] = false, false
+		marker := NewColor(7, 7, 7)
+		cp.Canvas.Write(0, 0, marker)
+		for x := range c.Width {
+			cp.Canvas.Write(x, 3, Black())
+		}
+		assert.NoError(t, cp.SaveFile(path))
+
+		resumed, err := c.RenderCheckpointed(w, path, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, resumed.At(0, 0), marker)
+		for x := range c.Width {
+			assert.Equal(t, resumed.At(x, 3), full.At(x, 3))
+		}
+	})
+
+	t.Run("refuses a checkpoint from another scene", func(t *testing.T) {
+		w, c := checkpointScene()
+		path := filepath.Join(t.TempDir(), "render.ckpt")
+		_, err := c.RenderCheckpointed(w, path, 0)
+		assert.NoError(t, err)
+
+		c.SamplesPerPixel = 3
+		_, err = c.RenderCheckpointed(w, path, 0)
+		assert.ErrorIs(t, err, ErrSceneChanged)
+	})
+}
@@ -5,7 +5,9 @@ import (
 	"math"
 )
 
-type Color = Tuple
+// Color is a linear RGB value. Channels are not limited to [0, 1] until the
+// color is written out.
+type Color Tuple
 
 func Black() Color {
 	return NewColor(0, 0, 0)
@@ -19,6 +21,36 @@ func NewColor(r, g, b float64) Color {
 	return Color{x: r, y: g, z: b, w: 0}
 }
 
+func (c Color) R() float64 {
+	return c.x
+}
+
+func (c Color) G() float64 {
+	return c.y
+}
+
+func (c Color) B() float64 {
+	return c.z
+}
+
+func (c Color) Add(d Color) Color {
+	return NewColor(c.x+d.x, c.y+d.y, c.z+d.z)
+}
+
+func (c Color) Sub(d Color) Color {
+	return NewColor(c.x-d.x, c.y-d.y, c.z-d.z)
+}
+
+func (c Color) Mul(t float64) Color {
+	return NewColor(c.x*t, c.y*t, c.z*t)
+}
+
+func (c Color) Div(t float64) Color {
+	return c.Mul(1 / t)
+}
+
+// Prod multiplies two colors channel by channel, as when light is filtered
+// by a surface.
 func (c Color) Prod(d Color) Color {
 	return NewColor(
 		c.x*d.x,
@@ -27,14 +59,54 @@ func (c Color) Prod(d Color) Color {
 	)
 }
 
-func (c Color) ToPpm() string {
-	r := int(math.Ceil(clamp(255.999 * c.x)))
-	g := int(math.Ceil(clamp(255.999 * c.y)))
-	b := int(math.Ceil(clamp(255.999 * c.z)))
+func (c Color) ApproxEqual(d Color, epsilon float64) bool {
+	return Tuple(c).ApproxEqual(Tuple(d), epsilon)
+}
+
+func (c Color) Min(d Color) Color {
+	return Color(Tuple(c).Min(Tuple(d)))
+}
+
+func (c Color) Max(d Color) Color {
+	return Color(Tuple(c).Max(Tuple(d)))
+}
+
+func (c Color) Abs() Color {
+	return Color(Tuple(c).Abs())
+}
+
+func (c Color) Lerp(d Color, t float64) Color {
+	return c.Add(d.Sub(c).Mul(t))
+}
+
+func (c Color) String() string {
+	return fmt.Sprintf("color(%g, %g, %g)", c.x, c.y, c.z)
+}
 
+func (c Color) MarshalJSON() ([]byte, error) {
+	return marshalTriple(c.x, c.y, c.z)
+}
+
+func (c *Color) UnmarshalJSON(data []byte) error {
+	r, g, b, err := unmarshalTriple(data, "color")
+	if err == nil {
+		*c = NewColor(r, g, b)
+	}
+	return err
+}
+
+func (c Color) ToPpm() string {
+	r, g, b := c.ToBytes()
 	return fmt.Sprintf("%d %d %d", r, g, b)
 }
 
+func (c Color) ToBytes() (uint8, uint8, uint8) {
+	r := uint8(math.Ceil(clamp(255.999 * c.x)))
+	g := uint8(math.Ceil(clamp(255.999 * c.y)))
+	b := uint8(math.Ceil(clamp(255.999 * c.z)))
+	return r, g, b
+}
+
 func clamp(x float64) float64 {
 	if x < 0.0 {
 		return 0.0
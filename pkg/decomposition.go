@@ -0,0 +1,92 @@
+package goray
+
+import "math"
+
+// Decomposition splits a transform into the scale, rotation and translation
+// that, applied in that order, rebuild it.
+type Decomposition struct {
+	Translation Vector
+	Rotation    Quaternion
+	Scale       Vector
+}
+
+// Decompose splits m into its translation, rotation and scale. It fails for
+// transforms that cannot be rebuilt that way: singular ones, ones with a
+// perspective bottom row, and ones with shear. A mirroring transform comes
+// back with a negative x scale.
+func (m Matrix) Decompose() (Decomposition, bool) {
+	if !m.IsAffine() {
+		return Decomposition{}, false
+	}
+	columns := [3]Vector{
+		NewVector(m[0], m[4], m[8]),
+		NewVector(m[1], m[5], m[9]),
+		NewVector(m[2], m[6], m[10]),
+	}
+	var scale [3]float64
+	for i, c := range columns {
+		scale[i] = c.Magnitude()
+		if scale[i] < Epsilon {
+			return Decomposition{}, false
+		}
+	}
+	if columns[0].Cross(columns[1]).Dot(columns[2]) < 0 {
+		scale[0] = -scale[0]
+	}
+	for i := range columns {
+		columns[i] = columns[i].Div(scale[i])
+	}
+	for i := range columns {
+		if math.Abs(columns[i].Dot(columns[(i+1)%3])) > Epsilon {
+			return Decomposition{}, false
+		}
+	}
+
+	rotation := NewMatrix(
+		columns[0].x, columns[1].x, columns[2].x, 0,
+		columns[0].y, columns[1].y, columns[2].y, 0,
+		columns[0].z, columns[1].z, columns[2].z, 0,
+		0, 0, 0, 1,
+	)
+	return Decomposition{
+		Translation: NewVector(m[3], m[7], m[11]),
+		Rotation:    QuaternionFromMatrix(rotation),
+		Scale:       NewVector(scale[0], scale[1], scale[2]),
+	}, true
+}
+
+// Matrix rebuilds the transform, the same as
+// Translation(...).Mul(d.Rotation.Matrix()).Mul(Scaling(...)).
+func (d Decomposition) Matrix() Matrix {
+	m := d.Rotation.Matrix()
+	s := [3]float64{d.Scale.x, d.Scale.y, d.Scale.z}
+	for row := range 3 {
+		for col := range 3 {
+			m[row*4+col] *= s[col]
+		}
+	}
+	m[3], m[7], m[11] = d.Translation.x, d.Translation.y, d.Translation.z
+	return m
+}
+
+// Slerp moves from m towards n by t, turning their rotations at a constant
+// rate. Unlike Lerp it does not shrink an object halfway through a turn.
+// Transforms that cannot be decomposed fall back to Lerp.
+func (m Matrix) Slerp(n Matrix, t float64) Matrix {
+	if t == 0 {
+		return m
+	}
+	if t == 1 {
+		return n
+	}
+	a, ok := m.Decompose()
+	b, okB := n.Decompose()
+	if !ok || !okB {
+		return m.Lerp(n, t)
+	}
+	return Decomposition{
+		Translation: a.Translation.Lerp(b.Translation, t),
+		Rotation:    a.Rotation.Slerp(b.Rotation, t),
+		Scale:       a.Scale.Lerp(b.Scale, t),
+	}.Matrix()
+}
@@ -0,0 +1,73 @@
+package goray
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecompose(t *testing.T) {
+	t.Run("splits a transform into translation, rotation and scale", func(t *testing.T) {
+		rotation := AxisAngle(NewVector(1, 2, 3), 0.7)
+		m := NewTransformBuilder().Scale(2, 3, 4).Rotate(rotation).Translate(1, -2, 5).Matrix()
+
+		d, ok := m.Decompose()
+		assert.True(t, ok)
+		assert.True(t, TuplesEqual(d.Translation, NewVector(1, -2, 5)))
+		assert.True(t, QuaternionsEqual(d.Rotation, rotation))
+		assert.True(t, TuplesEqual(d.Scale, NewVector(2, 3, 4)))
+		assert.True(t, MatricesEqual(d.Matrix(), m))
+	})
+
+	t.Run("rebuilds the identity exactly", func(t *testing.T) {
+		d, ok := IdentityMatrix().Decompose()
+		assert.True(t, ok)
+		assert.Equal(t, d.Matrix(), IdentityMatrix())
+	})
+
+	t.Run("a mirroring transform", func(t *testing.T) {
+		m := RotationZ(1).Mul(Scaling(1, -2, 1))
+
+		d, ok := m.Decompose()
+		assert.True(t, ok)
+		assert.True(t, d.Scale.x < 0)
+		assert.True(t, MatricesEqual(d.Matrix(), m))
+	})
+
+	testCases := map[string]Matrix{
+		"sheared":     Shearing(1, 0, 0, 0, 0, 0),
+		"singular":    Scaling(1, 0, 1),
+		"perspective": NewMatrix(1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 1, 0),
+	}
+	for description, m := range testCases {
+		t.Run(description, func(t *testing.T) {
+			_, ok := m.Decompose()
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestMatrixSlerp(t *testing.T) {
+	t.Run("turns without shrinking", func(t *testing.T) {
+		a := Scaling(2, 2, 2)
+		b := NewTransformBuilder().Scale(2, 2, 2).RotateY(math.Pi/2).Translate(4, 0, 0).Matrix()
+
+		halfway := a.Slerp(b, 0.5)
+		expected := NewTransformBuilder().Scale(2, 2, 2).RotateY(math.Pi/4).Translate(2, 0, 0).Matrix()
+		assert.True(t, MatricesEqual(halfway, expected))
+		assert.InDelta(t, halfway.MulVector(NewVector(1, 0, 0)).Magnitude(), 2, 0.00001)
+		assert.Less(t, a.Lerp(b, 0.5).MulVector(NewVector(1, 0, 0)).Magnitude(), 2.0)
+	})
+
+	t.Run("returns the ends exactly", func(t *testing.T) {
+		a, b := RotationX(0.3), Translation(1, 2, 3).Mul(RotationZ(2))
+		assert.Equal(t, a.Slerp(b, 0), a)
+		assert.Equal(t, a.Slerp(b, 1), b)
+	})
+
+	t.Run("falls back to Lerp when it cannot decompose", func(t *testing.T) {
+		a, b := IdentityMatrix(), Shearing(1, 0, 0, 0, 0, 0)
+		assert.Equal(t, a.Slerp(b, 0.5), a.Lerp(b, 0.5))
+	})
+}
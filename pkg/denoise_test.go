@@ -0,0 +1,81 @@
+package goray
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func noisyCanvas(width, height int, value func(x, y int) Color, noise float64) Canvas {
+	rng := rand.New(rand.NewPCG(1, 2))
+	c := blankCanvas(width, height)
+	for y := range height {
+		for x := range width {
+			n := (rng.Float64() - 0.5) * noise
+			c.Write(x, y, value(x, y).Add(NewColor(n, n, n)))
+		}
+	}
+	return c
+}
+
+func meanSquaredError(c Canvas, value func(x, y int) Color) float64 {
+	sum := 0.0
+	for y := range c.Height {
+		for x := range c.Width {
+			d := c.At(x, y).Sub(value(x, y))
+			sum += Tuple(d).Dot(Tuple(d))
+		}
+	}
+	return sum / float64(c.Width*c.Height)
+}
+
+func TestDenoiser(t *testing.T) {
+	split := func(x, _ int) Color {
+		if x < 8 {
+			return NewColor(0.2, 0.2, 0.2)
+		}
+		return NewColor(0.8, 0.8, 0.8)
+	}
+
+	t.Run("a flat canvas is unchanged", func(t *testing.T) {
+		c := noisyCanvas(4, 4, func(int, int) Color { return NewColor(0.3, 0.4, 0.5) }, 0)
+		out := NewDenoiser().Apply(c, nil)
+		for _, p := range out.Pixels {
+			assert.True(t, TuplesEqual(p, NewColor(0.3, 0.4, 0.5)))
+		}
+	})
+
+	t.Run("reduces noise", func(t *testing.T) {
+		c := noisyCanvas(16, 16, split, 0.2)
+		out := NewDenoiser().Apply(c, nil)
+		assert.Less(t, meanSquaredError(out, split), meanSquaredError(c, split)/2)
+	})
+
+	t.Run("keeps edges the guide marks", func(t *testing.T) {
+		// Two surfaces whose colors only differ by about as much as the noise.
+		subtle := func(x, _ int) Color {
+			if x < 8 {
+				return NewColor(0.45, 0.45, 0.45)
+			}
+			return NewColor(0.55, 0.55, 0.55)
+		}
+		c := noisyCanvas(16, 16, subtle, 0.2)
+
+		guide := NewAOVs(16, 16)
+		for y := range 16 {
+			for x := range 16 {
+				if x < 8 {
+					guide.Normal.Write(x, y, NewColor(0, 1, 0))
+				} else {
+					guide.Normal.Write(x, y, NewColor(1, 0, 0))
+				}
+			}
+		}
+
+		d := NewDenoiser()
+		unguided := d.Apply(c, nil)
+		guided := d.Apply(c, &guide)
+		assert.Less(t, meanSquaredError(guided, subtle), meanSquaredError(unguided, subtle))
+	})
+}
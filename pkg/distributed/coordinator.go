@@ -0,0 +1,172 @@
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	g "github.com/mikowitz/goray/pkg"
+)
+
+// Coordinator hands the tiles of a frame out to Workers, the base URLs of
+// running workers. Each worker is sent one tile at a time. A tile that fails
+// goes back in the queue for any worker to pick up, and the render gives up
+// once a single tile has failed more than Retries times.
+//
+// A worker that fails waits Backoff before taking another tile, twice as long
+// after each further failure in a row, and is dropped from the render once it
+// has failed WorkerFailures times in a row, so a dead worker cannot use up
+// the retries of tiles a healthy one would finish. With no WorkerFailures a
+// worker is never dropped.
+type Coordinator struct {
+	Workers        []string
+	TileSize       int
+	Retries        int
+	WorkerFailures int
+	Backoff        time.Duration
+	Client         *http.Client
+}
+
+func NewCoordinator(workers ...string) Coordinator {
+	return Coordinator{
+		Workers:        workers,
+		TileSize:       32,
+		Retries:        3,
+		WorkerFailures: 3,
+		Backoff:        100 * time.Millisecond,
+		Client:         http.DefaultClient,
+	}
+}
+
+type tileAttempt struct {
+	tile     Tile
+	failures int
+}
+
+// Render renders frame of the scene document and assembles the tiles the
+// workers return into a single canvas.
+func (co Coordinator) Render(ctx context.Context, document []byte, frame int) (g.Canvas, error) {
+	if len(co.Workers) == 0 {
+		return g.Canvas{}, fmt.Errorf("distributed: no workers")
+	}
+	scene, err := g.LoadScene(bytes.NewReader(document))
+	if err != nil {
+		return g.Canvas{}, err
+	}
+	_, camera := scene.Frame(frame)
+	canvas := g.NewCanvas(camera.Width, camera.AspectRatio)
+
+	tiles := SplitTiles(camera.Width, camera.Height, co.TileSize)
+	queue := make(chan tileAttempt, len(tiles))
+	for _, t := range tiles {
+		queue <- tileAttempt{tile: t}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	remaining := len(tiles)
+	working := len(co.Workers)
+
+	for _, worker := range co.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			failures := 0
+			for {
+				var attempt tileAttempt
+				select {
+				case <-ctx.Done():
+					return
+				case attempt = <-queue:
+				}
+
+				tile, err := co.renderTile(ctx, worker, Job{Scene: document, Frame: frame, Tile: attempt.tile})
+				if err != nil {
+					attempt.failures++
+					if attempt.failures > co.Retries {
+						cancel(fmt.Errorf("distributed: tile %+v failed %d times: %w", attempt.tile, attempt.failures, err))
+						return
+					}
+					queue <- attempt
+
+					failures++
+					if co.WorkerFailures > 0 && failures >= co.WorkerFailures {
+						mu.Lock()
+						working--
+						if working == 0 {
+							cancel(fmt.Errorf("distributed: every worker failed %d times in a row, last with: %w", failures, err))
+						}
+						mu.Unlock()
+						return
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(co.Backoff << min(failures-1, 6)):
+					}
+					continue
+				}
+				failures = 0
+
+				mu.Lock()
+				canvas.Paste(tile, attempt.tile.X, attempt.tile.Y)
+				remaining--
+				if remaining == 0 {
+					cancel(nil)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if remaining > 0 {
+		return g.Canvas{}, context.Cause(ctx)
+	}
+	return canvas, nil
+}
+
+func (co Coordinator) renderTile(ctx context.Context, worker string, job Job) (g.Canvas, error) {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return g.Canvas{}, err
+	}
+	url := strings.TrimSuffix(worker, "/") + "/tile"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return g.Canvas{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := co.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return g.Canvas{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return g.Canvas{}, fmt.Errorf("%s: %s: %s", worker, resp.Status, strings.TrimSpace(string(message)))
+	}
+	tile, err := g.LoadPFM(resp.Body)
+	if err != nil {
+		return g.Canvas{}, fmt.Errorf("%s: %w", worker, err)
+	}
+	if tile.Width != job.Tile.Width || tile.Height != job.Tile.Height {
+		return g.Canvas{}, fmt.Errorf("%s: returned a %dx%d tile for %+v", worker, tile.Width, tile.Height, job.Tile)
+	}
+	return tile, nil
+}
@@ -0,0 +1,204 @@
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	g "github.com/mikowitz/goray/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+const testScene = `{
+	"camera": {
+		"width": 20, "aspect_ratio": 2, "field_of_view": 1.0471975512,
+		"from": [0, 1.5, -5], "to": [0, 1, 0], "up": [0, 1, 0],
+		"samples": 2, "seed": 4
+	},
+	"light": {"position": [-10, 10, -10], "intensity": [1, 1, 1]},
+	"objects": [
+		{"type": "plane", "material": {"pattern": {"type": "checkers", "a": [0, 0, 0], "b": [1, 1, 1]}}},
+		{"type": "sphere", "transform": [{"translate": [0, 1, 0]}], "material": {"color": [1, 0, 0.5]}}
+	]
+}`
+
+func localRender(t *testing.T, tile Tile) g.Canvas {
+	scene, err := g.LoadScene(strings.NewReader(testScene))
+	assert.NoError(t, err)
+	w, c := scene.Frame(0)
+	if tile == (Tile{}) {
+		return c.Render(w)
+	}
+	return c.RenderTile(w, tile.X, tile.Y, tile.Width, tile.Height)
+}
+
+func assertCanvasesMatch(t *testing.T, got, want g.Canvas) {
+	assert.Equal(t, got.Width, want.Width)
+	assert.Equal(t, got.Height, want.Height)
+	assert.Less(t, g.RMSE(got, want), 1e-6)
+}
+
+// flaky fails its first failures requests before handing the rest to next.
+func flaky(failures int32, next http.Handler) http.Handler {
+	var count atomic.Int32
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if count.Add(1) <= failures {
+			http.Error(w, "worker unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestSplitTiles(t *testing.T) {
+	tiles := SplitTiles(5, 3, 2)
+	assert.Equal(t, tiles, []Tile{
+		{0, 0, 2, 2}, {2, 0, 2, 2}, {4, 0, 1, 2},
+		{0, 2, 2, 1}, {2, 2, 2, 1}, {4, 2, 1, 1},
+	})
+}
+
+func TestWorker(t *testing.T) {
+	server := httptest.NewServer(NewWorker())
+	defer server.Close()
+
+	post := func(job Job) *http.Response {
+		body, _ := json.Marshal(job)
+		resp, err := http.Post(server.URL+"/tile", "application/json", bytes.NewReader(body))
+		assert.NoError(t, err)
+		return resp
+	}
+
+	t.Run("renders the requested tile", func(t *testing.T) {
+		tile := Tile{X: 4, Y: 2, Width: 3, Height: 2}
+		resp := post(Job{Scene: json.RawMessage(testScene), Tile: tile})
+		defer resp.Body.Close()
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		canvas, err := g.LoadPFM(resp.Body)
+		assert.NoError(t, err)
+		assertCanvasesMatch(t, canvas, localRender(t, tile))
+	})
+
+	t.Run("rejects tiles outside the image", func(t *testing.T) {
+		resp := post(Job{Scene: json.RawMessage(testScene), Tile: Tile{X: 18, Y: 0, Width: 4, Height: 2}})
+		resp.Body.Close()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("rejects invalid scenes", func(t *testing.T) {
+		resp := post(Job{Scene: json.RawMessage(`{"objects": []}`), Tile: Tile{Width: 1, Height: 1}})
+		resp.Body.Close()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("rejects scenes beyond its limits", func(t *testing.T) {
+		for _, scene := range []string{
+			strings.Replace(testScene, `"width": 20`, `"width": 100000`, 1),
+			strings.Replace(testScene, `"samples": 2`, `"samples": 100000`, 1),
+		} {
+			resp := post(Job{Scene: json.RawMessage(scene), Tile: Tile{Width: 1, Height: 1}})
+			resp.Body.Close()
+			assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("rejects scenes that refer to files", func(t *testing.T) {
+		scene := strings.Replace(testScene, `"objects"`, `"background": {"type": "environment", "path": "/etc/passwd"}, "objects"`, 1)
+		resp := post(Job{Scene: json.RawMessage(scene), Tile: Tile{Width: 1, Height: 1}})
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+		assert.Contains(t, string(body), "may not refer to files")
+	})
+
+	t.Run("rejects oversized requests", func(t *testing.T) {
+		body := bytes.Repeat([]byte(" "), maxJobSize+1)
+		resp, err := http.Post(server.URL+"/tile", "application/json", bytes.NewReader(body))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
+}
+
+func TestCoordinator(t *testing.T) {
+	t.Run("assembles tiles from several workers", func(t *testing.T) {
+		a := httptest.NewServer(NewWorker())
+		defer a.Close()
+		b := httptest.NewServer(NewWorker())
+		defer b.Close()
+
+		co := NewCoordinator(a.URL, b.URL)
+		co.TileSize = 4
+		canvas, err := co.Render(context.Background(), []byte(testScene), 0)
+		assert.NoError(t, err)
+		assertCanvasesMatch(t, canvas, localRender(t, Tile{}))
+	})
+
+	t.Run("retries tiles that fail", func(t *testing.T) {
+		healthy := httptest.NewServer(NewWorker())
+		defer healthy.Close()
+		unreliable := httptest.NewServer(flaky(5, NewWorker()))
+		defer unreliable.Close()
+
+		co := NewCoordinator(unreliable.URL, healthy.URL)
+		co.TileSize = 8
+		co.Backoff = time.Millisecond
+		canvas, err := co.Render(context.Background(), []byte(testScene), 0)
+		assert.NoError(t, err)
+		assertCanvasesMatch(t, canvas, localRender(t, Tile{}))
+	})
+
+	t.Run("gives up after too many failures", func(t *testing.T) {
+		broken := httptest.NewServer(flaky(1000, NewWorker()))
+		defer broken.Close()
+
+		co := NewCoordinator(broken.URL)
+		co.Retries = 2
+		co.Backoff = time.Millisecond
+		_, err := co.Render(context.Background(), []byte(testScene), 0)
+		assert.ErrorContains(t, err, "failed 3 times")
+	})
+
+	t.Run("drops a worker that keeps failing", func(t *testing.T) {
+		healthy := httptest.NewServer(NewWorker())
+		defer healthy.Close()
+		var requests atomic.Int32
+		dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			http.Error(w, "down", http.StatusServiceUnavailable)
+		}))
+		defer dead.Close()
+
+		co := NewCoordinator(dead.URL, healthy.URL)
+		co.TileSize = 4
+		co.Backoff = time.Millisecond
+		canvas, err := co.Render(context.Background(), []byte(testScene), 0)
+		assert.NoError(t, err)
+		assertCanvasesMatch(t, canvas, localRender(t, Tile{}))
+		assert.LessOrEqual(t, requests.Load(), int32(co.WorkerFailures))
+	})
+
+	t.Run("gives up when every worker has been dropped", func(t *testing.T) {
+		dead := httptest.NewServer(flaky(1000, NewWorker()))
+		dead.Close()
+
+		co := NewCoordinator(dead.URL)
+		co.Retries = 10
+		co.Backoff = time.Millisecond
+		_, err := co.Render(context.Background(), []byte(testScene), 0)
+		assert.ErrorContains(t, err, "every worker failed 3 times")
+	})
+
+	t.Run("needs workers", func(t *testing.T) {
+		_, err := NewCoordinator().Render(context.Background(), []byte(testScene), 0)
+		assert.Error(t, err)
+	})
+}
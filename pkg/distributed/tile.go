@@ -0,0 +1,41 @@
+// Package distributed renders a frame across several goray worker processes.
+// A Coordinator splits the image into tiles and posts each one, together
+// with the scene document, to a worker's /tile endpoint. The worker answers
+// with the tile's pixels as a Portable Float Map, and the coordinator pastes
+// them into the final canvas.
+package distributed
+
+import "encoding/json"
+
+type Tile struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// SplitTiles covers a width by height image with tiles of at most size
+// pixels a side, row by row from the top left.
+func SplitTiles(width, height, size int) []Tile {
+	var tiles []Tile
+	for y := 0; y < height; y += size {
+		for x := 0; x < width; x += size {
+			tiles = append(tiles, Tile{
+				X:      x,
+				Y:      y,
+				Width:  min(size, width-x),
+				Height: min(size, height-y),
+			})
+		}
+	}
+	return tiles
+}
+
+// Job is the body of a tile request. Scene is a scene document in the format
+// goray.LoadScene reads. Paths inside it are resolved on the worker, so
+// anything it refers to, such as environment maps, must exist there too.
+type Job struct {
+	Scene json.RawMessage `json:"scene"`
+	Frame int             `json:"frame"`
+	Tile  Tile            `json:"tile"`
+}
@@ -0,0 +1,104 @@
+package distributed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	g "github.com/mikowitz/goray/pkg"
+)
+
+// maxJobSize caps how much of a request body is read as a tile job.
+const maxJobSize = 64 << 20
+
+// Worker renders tiles posted to it, one at a time since shapes keep scratch
+// state while they are intersected. It remembers the last scene it loaded,
+// prepared for rendering, so the many tiles of one frame only parse the
+// document once. Scenes must keep within the worker's Limits, and may only
+// refer to files when AllowFiles is set.
+type Worker struct {
+	g.Limits
+	AllowFiles bool
+
+	mu     sync.Mutex
+	hash   [32]byte
+	frame  int
+	world  g.World
+	camera g.Camera
+}
+
+func NewWorker() *Worker {
+	return &Worker{Limits: g.NewLimits()}
+}
+
+func (wk *Worker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/tile" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "tiles must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var job Job
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJobSize)).Decode(&job); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tile, err := wk.render(job)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := tile.WritePFM(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/x-portable-floatmap")
+	w.Write(body.Bytes())
+}
+
+func (wk *Worker) render(job Job) (g.Canvas, error) {
+	wk.mu.Lock()
+	defer wk.mu.Unlock()
+
+	world, camera, err := wk.scene(job)
+	if err != nil {
+		return g.Canvas{}, err
+	}
+	t := job.Tile
+	if t.X < 0 || t.Y < 0 || t.Width <= 0 || t.Height <= 0 || t.X+t.Width > camera.Width || t.Y+t.Height > camera.Height {
+		return g.Canvas{}, fmt.Errorf("tile %+v is outside the %dx%d image", t, camera.Width, camera.Height)
+	}
+	return camera.RenderTile(world, t.X, t.Y, t.Width, t.Height), nil
+}
+
+// scene must be called with mu held.
+func (wk *Worker) scene(job Job) (g.World, g.Camera, error) {
+	hash := sha256.Sum256(job.Scene)
+	if wk.camera.Width > 0 && hash == wk.hash && job.Frame == wk.frame {
+		return wk.world, wk.camera, nil
+	}
+
+	load := g.LoadSceneWithoutFiles
+	if wk.AllowFiles {
+		load = g.LoadScene
+	}
+	scene, err := load(bytes.NewReader(job.Scene))
+	if err != nil {
+		return g.World{}, g.Camera{}, err
+	}
+	world, camera := scene.Frame(job.Frame)
+	if err := wk.Check(world, camera); err != nil {
+		return g.World{}, g.Camera{}, err
+	}
+	wk.world, wk.camera = world.Prepare(), camera
+	wk.hash, wk.frame = hash, job.Frame
+	return wk.world, wk.camera, nil
+}
@@ -0,0 +1,138 @@
+package goray
+
+import "math"
+
+// Kernel is a square convolution kernel of odd Size with its Weights laid out
+// row by row.
+type Kernel struct {
+	Size    int
+	Weights []float64
+}
+
+func NewKernel(size int, weights ...float64) Kernel {
+	if size%2 == 0 || len(weights) != size*size {
+		panic("kernel needs an odd size and size*size weights")
+	}
+	return Kernel{Size: size, Weights: weights}
+}
+
+func SharpenKernel() Kernel {
+	return NewKernel(3,
+		0, -1, 0,
+		-1, 5, -1,
+		0, -1, 0,
+	)
+}
+
+func NewGaussianKernel(sigma float64) Kernel {
+	weights := gaussianWeights(sigma)
+	size := len(weights)
+	k := Kernel{Size: size, Weights: make([]float64, size*size)}
+	for y, wy := range weights {
+		for x, wx := range weights {
+			k.Weights[y*size+x] = wx * wy
+		}
+	}
+	return k
+}
+
+// gaussianWeights returns a normalized one dimensional Gaussian that reaches
+// out three standard deviations either side of its centre. A Gaussian with no
+// spread, or a nonsensical one, leaves the image as it is.
+func gaussianWeights(sigma float64) []float64 {
+	if !(sigma > 0) {
+		return []float64{1}
+	}
+	radius := max(int(math.Ceil(3.0*sigma)), 1)
+	weights := make([]float64, 2*radius+1)
+	sum := 0.0
+	for i := range weights {
+		d := float64(i - radius)
+		weights[i] = math.Exp(-d * d / (2.0 * sigma * sigma))
+		sum += weights[i]
+	}
+	for i := range weights {
+		weights[i] /= sum
+	}
+	return weights
+}
+
+// clampedAt reads the pixel at (x, y), extending the edges of the canvas
+// outwards for coordinates that fall off it.
+func (c Canvas) clampedAt(x, y int) Color {
+	return c.At(min(max(x, 0), c.Width-1), min(max(y, 0), c.Height-1))
+}
+
+func (c Canvas) Convolve(k Kernel) Canvas {
+	out := blankCanvas(c.Width, c.Height)
+	r := k.Size / 2
+	for y := range c.Height {
+		for x := range c.Width {
+			sum := Black()
+			for ky := range k.Size {
+				for kx := range k.Size {
+					sum = sum.Add(c.clampedAt(x+kx-r, y+ky-r).Mul(k.Weights[ky*k.Size+kx]))
+				}
+			}
+			out.Write(x, y, sum)
+		}
+	}
+	return out
+}
+
+// GaussianBlur blurs the canvas with a Gaussian of the given standard
+// deviation in pixels. It runs as two one dimensional passes, which gives the
+// same result as convolving with NewGaussianKernel but much faster.
+func (c Canvas) GaussianBlur(sigma float64) Canvas {
+	weights := gaussianWeights(sigma)
+	r := len(weights) / 2
+
+	horizontal := blankCanvas(c.Width, c.Height)
+	for y := range c.Height {
+		for x := range c.Width {
+			sum := Black()
+			for i, w := range weights {
+				sum = sum.Add(c.clampedAt(x+i-r, y).Mul(w))
+			}
+			horizontal.Write(x, y, sum)
+		}
+	}
+
+	out := blankCanvas(c.Width, c.Height)
+	for y := range c.Height {
+		for x := range c.Width {
+			sum := Black()
+			for i, w := range weights {
+				sum = sum.Add(horizontal.clampedAt(x, y+i-r).Mul(w))
+			}
+			out.Write(x, y, sum)
+		}
+	}
+	return out
+}
+
+func (c Canvas) Sharpen() Canvas {
+	return c.Convolve(SharpenKernel())
+}
+
+// Bloom makes highlights bleed into their surroundings. Whatever each channel
+// has above threshold is blurred by sigma pixels, scaled by strength and added
+// back on top of the image, so it needs to run on linear values before they
+// are tone mapped.
+func (c Canvas) Bloom(threshold, sigma, strength float64) Canvas {
+	bright := blankCanvas(c.Width, c.Height)
+	for i, p := range c.Pixels {
+		bright.Pixels[i] = NewColor(
+			math.Max(p.x-threshold, 0.0),
+			math.Max(p.y-threshold, 0.0),
+			math.Max(p.z-threshold, 0.0),
+		)
+	}
+	glow := bright.GaussianBlur(sigma)
+
+	out := blankCanvas(c.Width, c.Height)
+	for i, p := range c.Pixels {
+		out.Pixels[i] = p.Add(glow.Pixels[i].Mul(strength))
+	}
+	return out
+}
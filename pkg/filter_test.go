@@ -0,0 +1,138 @@
+package goray
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func impulseCanvas(size int, value Color) Canvas {
+	c := blankCanvas(size, size)
+	c.Write(size/2, size/2, value)
+	return c
+}
+
+func canvasSum(c Canvas) Color {
+	sum := Black()
+	for _, p := range c.Pixels {
+		sum = sum.Add(p)
+	}
+	return sum
+}
+
+func TestNewKernel(t *testing.T) {
+	assert.Panics(t, func() { NewKernel(2, 1, 1, 1, 1) })
+	assert.Panics(t, func() { NewKernel(3, 1) })
+}
+
+func TestNewGaussianKernel(t *testing.T) {
+	k := NewGaussianKernel(1)
+	assert.Equal(t, k.Size, 7)
+
+	sum := 0.0
+	for _, w := range k.Weights {
+		sum += w
+	}
+	assert.InDelta(t, sum, 1.0, 1e-9)
+	assert.Greater(t, k.Weights[3*7+3], k.Weights[3*7+4])
+	assert.InDelta(t, k.Weights[3*7+2], k.Weights[3*7+4], 1e-12)
+}
+
+func TestNewGaussianKernelWithoutSpread(t *testing.T) {
+	for _, sigma := range []float64{0, -1, math.NaN()} {
+		assert.Equal(t, NewGaussianKernel(sigma), NewKernel(1, 1))
+	}
+
+	c := impulseCanvas(5, NewColor(1, 2, 3))
+	assert.Equal(t, c.GaussianBlur(0), c)
+}
+
+func TestConvolve(t *testing.T) {
+	t.Run("an identity kernel leaves the canvas alone", func(t *testing.T) {
+		c := impulseCanvas(3, NewColor(1, 2, 3))
+		out := c.Convolve(NewKernel(1, 1))
+		assert.Equal(t, out.Pixels, c.Pixels)
+	})
+
+	t.Run("the kernel is not flipped", func(t *testing.T) {
+		c := impulseCanvas(3, White())
+		out := c.Convolve(NewKernel(3,
+			0, 0, 0,
+			0, 0, 1,
+			0, 0, 0,
+		))
+		assert.Equal(t, out.At(0, 1), White())
+		assert.Equal(t, out.At(1, 1), Black())
+	})
+
+	t.Run("edges are extended", func(t *testing.T) {
+		c := blankCanvas(2, 1)
+		c.Write(0, 0, White())
+		out := c.Convolve(NewKernel(3,
+			0, 0, 0,
+			1, 0, 0,
+			0, 0, 0,
+		))
+		assert.Equal(t, out.At(0, 0), White())
+		assert.Equal(t, out.At(1, 0), White())
+	})
+}
+
+func TestGaussianBlur(t *testing.T) {
+	c := impulseCanvas(15, NewColor(1, 2, 3))
+	blurred := c.GaussianBlur(1.5)
+
+	t.Run("matches convolving with a gaussian kernel", func(t *testing.T) {
+		convolved := c.Convolve(NewGaussianKernel(1.5))
+		for i := range blurred.Pixels {
+			assert.True(t, TuplesEqual(blurred.Pixels[i], convolved.Pixels[i]))
+		}
+	})
+
+	t.Run("preserves energy away from the edges", func(t *testing.T) {
+		assert.True(t, TuplesEqual(canvasSum(blurred), NewColor(1, 2, 3)))
+	})
+
+	t.Run("spreads the impulse symmetrically", func(t *testing.T) {
+		assert.Less(t, blurred.At(7, 7).x, 1.0)
+		assert.True(t, TuplesEqual(blurred.At(6, 7), blurred.At(8, 7)))
+		assert.True(t, TuplesEqual(blurred.At(7, 6), blurred.At(6, 7)))
+	})
+}
+
+func TestSharpen(t *testing.T) {
+	t.Run("flat regions are unchanged", func(t *testing.T) {
+		c := blankCanvas(3, 3)
+		for i := range c.Pixels {
+			c.Pixels[i] = NewColor(0.5, 0.5, 0.5)
+		}
+		out := c.Sharpen()
+		for _, p := range out.Pixels {
+			assert.True(t, TuplesEqual(p, NewColor(0.5, 0.5, 0.5)))
+		}
+	})
+
+	t.Run("edges gain contrast", func(t *testing.T) {
+		c := impulseCanvas(3, NewColor(0.5, 0.5, 0.5))
+		out := c.Sharpen()
+		assert.True(t, TuplesEqual(out.At(1, 1), NewColor(2.5, 2.5, 2.5)))
+		assert.True(t, TuplesEqual(out.At(1, 0), NewColor(-0.5, -0.5, -0.5)))
+	})
+}
+
+func TestBloom(t *testing.T) {
+	t.Run("values below the threshold are left alone", func(t *testing.T) {
+		c := impulseCanvas(9, NewColor(0.9, 0.9, 0.9))
+		assert.Equal(t, c.Bloom(1, 1, 1).Pixels, c.Pixels)
+	})
+
+	t.Run("highlights spread into their neighbours", func(t *testing.T) {
+		c := impulseCanvas(15, NewColor(5, 1, 0))
+		out := c.Bloom(1, 1, 0.5)
+		assert.Greater(t, out.At(8, 7).x, 0.0)
+		assert.Equal(t, out.At(8, 7).y, 0.0)
+		assert.True(t, TuplesEqual(canvasSum(out), NewColor(7, 1, 0)))
+		assert.False(t, math.IsNaN(out.At(0, 0).x))
+	})
+}
@@ -0,0 +1,30 @@
+package goray
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// sampleLobe returns a direction around axis drawn from a cosine-power lobe.
+// The exponent follows the usual Blinn-Phong mapping from roughness, so a
+// roughness of 0 gives back axis and a roughness of 1 covers the whole
+// hemisphere.
+func sampleLobe(axis Vector, roughness float64, rng *rand.Rand) Vector {
+	if roughness <= 0.0 {
+		return axis
+	}
+	axis = axis.Normalize()
+	alpha := math.Min(roughness, 1.0)
+	alpha *= alpha
+	exponent := 2.0/(alpha*alpha) - 2.0
+
+	cosTheta := math.Pow(rng.Float64(), 1.0/(exponent+1.0))
+	sinTheta := math.Sqrt(math.Max(0.0, 1.0-cosTheta*cosTheta))
+	phi := 2.0 * math.Pi * rng.Float64()
+
+	t, b := orthonormalBasis(axis)
+	return t.Mul(sinTheta * math.Cos(phi)).
+		Add(b.Mul(sinTheta * math.Sin(phi))).
+		Add(axis.Mul(cosTheta)).
+		Normalize()
+}
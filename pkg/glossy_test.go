@@ -0,0 +1,48 @@
+package goray
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleLobe(t *testing.T) {
+	axis := NewVector(0, 1, 0)
+
+	t.Run("with no roughness", func(t *testing.T) {
+		rng := rand.New(rand.NewPCG(0, 0))
+		assert.Equal(t, sampleLobe(axis, 0.0, rng), axis)
+	})
+
+	t.Run("stays within the hemisphere around the axis", func(t *testing.T) {
+		rng := rand.New(rand.NewPCG(0, 0))
+		for range 100 {
+			v := sampleLobe(axis, 1.0, rng)
+			assert.InDelta(t, v.Magnitude(), 1.0, 0.00001)
+			assert.GreaterOrEqual(t, v.Dot(axis), 0.0)
+		}
+	})
+
+	t.Run("narrows as roughness decreases", func(t *testing.T) {
+		rng := rand.New(rand.NewPCG(0, 0))
+		rough, smooth := 0.0, 0.0
+		for range 100 {
+			rough += sampleLobe(axis, 0.8, rng).Dot(axis)
+			smooth += sampleLobe(axis, 0.1, rng).Dot(axis)
+		}
+		assert.Greater(t, smooth, rough)
+		assert.Greater(t, smooth/100, 0.99)
+	})
+
+	t.Run("has the width its roughness gives", func(t *testing.T) {
+		// A roughness of 0.5 gives a cosine-power exponent of 30, whose
+		// mean cosine is 31/32.
+		rng := rand.New(rand.NewPCG(0, 0))
+		sum := 0.0
+		for range 10000 {
+			sum += sampleLobe(axis, 0.5, rng).Dot(axis)
+		}
+		assert.InDelta(t, sum/10000, 31.0/32.0, 0.001)
+	})
+}
@@ -0,0 +1,169 @@
+// Package golden compares rendered canvases against reference images stored
+// alongside the tests. Run the tests with -update to write the current output
+// as the new references.
+package golden
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	g "github.com/mikowitz/goray/pkg"
+)
+
+var update = flag.Bool("update", false, "rewrite golden reference images with the current output")
+
+// Dir is where references are read from and written to, relative to the
+// directory of the package under test.
+var Dir = filepath.Join("testdata", "golden")
+
+// Options sets how far a render may drift from its reference. Tolerance is
+// the largest difference any channel of a pixel may have before the pixel
+// counts towards MaxDifferingPixels. The image as a whole must also reach
+// MinPSNR and MinSSIM.
+type Options struct {
+	Tolerance          float64
+	MaxDifferingPixels int
+	MinPSNR            float64
+	MinSSIM            float64
+}
+
+// NewOptions requires every pixel to be within Tolerance of the reference.
+func NewOptions() Options {
+	return Options{
+		Tolerance:          2.0 / 255.0,
+		MaxDifferingPixels: 0,
+		MinPSNR:            40.0,
+		MinSSIM:            0.99,
+	}
+}
+
+// AllowingFlippedPixels lets up to n pixels differ from the reference by any
+// amount, for renders where a change in the last bit of a ray can flip an
+// aliased pixel, such as one on the edge of a checker square, to the other
+// colour. MinPSNR and MinSSIM are lowered far enough for n pixels flipping
+// between black and white in a 32 by 18 render to pass.
+func (o Options) AllowingFlippedPixels(n int) Options {
+	if n > 0 {
+		o.MaxDifferingPixels = n
+		o.MinPSNR = min(o.MinPSNR, 20.0)
+		o.MinSSIM = min(o.MinSSIM, 0.9)
+	}
+	return o
+}
+
+type Result struct {
+	RMSE, PSNR, SSIM float64
+	DifferingPixels  int
+}
+
+func Compare(got, want g.Canvas, opts Options) Result {
+	return Result{
+		RMSE:            g.RMSE(got, want),
+		PSNR:            g.PSNR(got, want),
+		SSIM:            g.SSIM(got, want),
+		DifferingPixels: g.DifferingPixels(got, want, opts.Tolerance),
+	}
+}
+
+// Failures lists every way the result falls short of opts.
+func (r Result) Failures(opts Options) []string {
+	var failures []string
+	if r.DifferingPixels > opts.MaxDifferingPixels {
+		failures = append(failures, fmt.Sprintf("%d pixels differ by more than %g, at most %d may", r.DifferingPixels, opts.Tolerance, opts.MaxDifferingPixels))
+	}
+	if r.PSNR < opts.MinPSNR {
+		failures = append(failures, fmt.Sprintf("PSNR %.2fdB is below %.2fdB", r.PSNR, opts.MinPSNR))
+	}
+	if r.SSIM < opts.MinSSIM {
+		failures = append(failures, fmt.Sprintf("SSIM %.4f is below %.4f", r.SSIM, opts.MinSSIM))
+	}
+	return failures
+}
+
+// Assert compares got with the reference image name.png in Dir. The canvas
+// is quantized to 8 bits first so it is measured exactly as it would be
+// stored. When the comparison fails a heatmap of the differences and the
+// actual output are written next to the reference as name.diff.png and
+// name.actual.png.
+func Assert(t testing.TB, name string, got g.Canvas, opts Options) {
+	t.Helper()
+	path := filepath.Join(Dir, name+".png")
+
+	if *update {
+		if err := os.MkdirAll(Dir, 0o755); err != nil {
+			t.Fatalf("golden: %v", err)
+		}
+		if err := writePNG(path, got); err != nil {
+			t.Fatalf("golden: %v", err)
+		}
+		t.Logf("golden: updated %s", path)
+		return
+	}
+
+	want, err := readPNG(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("golden: no reference at %s, run the tests with -update to create it", path)
+	}
+	if err != nil {
+		t.Fatalf("golden: %v", err)
+	}
+	if got.Width != want.Width || got.Height != want.Height {
+		t.Fatalf("golden: %s is %dx%d but the render is %dx%d", path, want.Width, want.Height, got.Width, got.Height)
+	}
+
+	quantized := quantize(got)
+	result := Compare(quantized, want, opts)
+	failures := result.Failures(opts)
+	if len(failures) == 0 {
+		return
+	}
+
+	diffPath := filepath.Join(Dir, name+".diff.png")
+	actualPath := filepath.Join(Dir, name+".actual.png")
+	if err := writePNG(diffPath, g.DiffHeatmap(quantized, want)); err != nil {
+		t.Errorf("golden: %v", err)
+	}
+	if err := writePNG(actualPath, got); err != nil {
+		t.Errorf("golden: %v", err)
+	}
+	for _, failure := range failures {
+		t.Errorf("golden: %s: %s", name, failure)
+	}
+	t.Errorf("golden: %s: RMSE %.5f, see %s and %s", name, result.RMSE, diffPath, actualPath)
+}
+
+// quantize reads c back the way it would be after a trip through a PNG.
+func quantize(c g.Canvas) g.Canvas {
+	return g.CanvasFromImage(c.ToImage())
+}
+
+func readPNG(path string) (g.Canvas, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return g.Canvas{}, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return g.Canvas{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return g.CanvasFromImage(img), nil
+}
+
+func writePNG(path string, c g.Canvas) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := c.WritePNG(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,147 @@
+package golden
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	g "github.com/mikowitz/goray/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder stands in for *testing.T so failing assertions can be inspected.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Logf(string, ...any) {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.fatal = true
+	runtime.Goexit()
+}
+
+// assertWith runs Assert against a recorder on its own goroutine, which a
+// fatal failure ends just as it would end a real test.
+func assertWith(t *testing.T, name string, got g.Canvas) *recorder {
+	r := &recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Assert(r, name, got, NewOptions())
+	}()
+	<-done
+	return r
+}
+
+func gradient(width, height int, brightness float64) g.Canvas {
+	c := g.NewCanvas(width, float64(width)/float64(height))
+	for y := range height {
+		for x := range width {
+			v := brightness * float64(x+y) / float64(width+height)
+			c.Write(x, y, g.NewColor(v, v/2, 1-v))
+		}
+	}
+	return c
+}
+
+func useTempDir(t *testing.T) {
+	dir := Dir
+	Dir = t.TempDir()
+	t.Cleanup(func() { Dir = dir })
+}
+
+func setUpdate(t *testing.T, value bool) {
+	previous := *update
+	*update = value
+	t.Cleanup(func() { *update = previous })
+}
+
+func TestAssert(t *testing.T) {
+	t.Run("a missing reference fails", func(t *testing.T) {
+		useTempDir(t)
+		r := assertWith(t, "missing", gradient(8, 8, 1))
+		assert.True(t, r.fatal)
+	})
+
+	t.Run("update writes a reference that then matches", func(t *testing.T) {
+		useTempDir(t)
+		setUpdate(t, true)
+		Assert(t, "gradient", gradient(8, 8, 1), NewOptions())
+		assert.FileExists(t, filepath.Join(Dir, "gradient.png"))
+
+		*update = false
+		r := assertWith(t, "gradient", gradient(8, 8, 1))
+		assert.Empty(t, r.errors)
+	})
+
+	t.Run("a differing render fails and writes a heatmap", func(t *testing.T) {
+		useTempDir(t)
+		setUpdate(t, true)
+		Assert(t, "gradient", gradient(8, 8, 1), NewOptions())
+
+		*update = false
+		r := assertWith(t, "gradient", gradient(8, 8, 0.5))
+		assert.NotEmpty(t, r.errors)
+		assert.False(t, r.fatal)
+		assert.FileExists(t, filepath.Join(Dir, "gradient.diff.png"))
+		assert.FileExists(t, filepath.Join(Dir, "gradient.actual.png"))
+	})
+
+	t.Run("a render of the wrong size fails", func(t *testing.T) {
+		useTempDir(t)
+		setUpdate(t, true)
+		Assert(t, "gradient", gradient(8, 8, 1), NewOptions())
+
+		*update = false
+		r := assertWith(t, "gradient", gradient(8, 4, 1))
+		assert.True(t, r.fatal)
+	})
+}
+
+func TestResultFailures(t *testing.T) {
+	opts := NewOptions()
+	assert.Empty(t, Result{PSNR: 50, SSIM: 1}.Failures(opts))
+	assert.Len(t, Result{PSNR: 15, SSIM: 0.5, DifferingPixels: 5}.Failures(opts), 3)
+}
+
+func checkers(width, height int) g.Canvas {
+	c := g.NewCanvas(width, float64(width)/float64(height))
+	for y := range height {
+		for x := range width {
+			v := float64((x/4 + y/4) % 2)
+			c.Write(x, y, g.NewColor(v, v, v))
+		}
+	}
+	return c
+}
+
+func TestFlippedPixels(t *testing.T) {
+	want := checkers(32, 18)
+	// Flip pixels on the edges of squares to the neighbouring square's
+	// colour, as a last-bit change in a ray can.
+	flip := func(n int) g.Canvas {
+		got := checkers(32, 18)
+		for i := range n {
+			x, y := 4*(i+1), 2+4*(i%3)
+			got.Write(x, y, want.At(x-1, y))
+		}
+		return got
+	}
+
+	assert.NotEmpty(t, Compare(flip(1), want, NewOptions()).Failures(NewOptions()))
+
+	opts := NewOptions().AllowingFlippedPixels(4)
+	assert.Empty(t, Compare(flip(4), want, opts).Failures(opts))
+	assert.NotEmpty(t, Compare(flip(5), want, opts).Failures(opts))
+}
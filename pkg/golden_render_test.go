@@ -0,0 +1,61 @@
+package goray_test
+
+import (
+	"math"
+	"testing"
+
+	g "github.com/mikowitz/goray/pkg"
+	"github.com/mikowitz/goray/pkg/golden"
+)
+
+func checkeredRoom() g.World {
+	checkers := g.NewCheckersPattern(g.NewColor(0, 0, 0), g.NewColor(1, 1, 1))
+	floor := g.NewPlane()
+	fm := g.NewMaterial()
+	fm.Pattern = &checkers
+	fm.Reflective = 0.25
+	floor.SetMaterial(fm)
+
+	stripes := g.NewStripePattern(g.NewColor(0.9, 0.9, 0.9), g.NewColor(0.2, 0.2, 0.2))
+	stripes.SetTransform(g.Scaling(0.33, 0.33, 0.33))
+	wall := g.NewPlane()
+	wall.SetTransform(g.Translation(0, 0, 10).Mul(g.RotationX(math.Pi / 2)))
+	wall.Material.Pattern = &stripes
+
+	red := g.NewSolidPattern(g.NewColor(1, 0, 0.5))
+	ball := g.NewSphere()
+	ball.SetTransform(g.Translation(-1, 1, 0))
+	bm := g.NewMaterial()
+	bm.Pattern = &red
+	bm.Shininess = 50
+	ball.SetMaterial(bm)
+
+	glass := g.GlassSphere()
+	glass.SetTransform(g.Translation(1.2, 0.75, -1).Mul(g.Scaling(0.75, 0.75, 0.75)))
+
+	w := g.NewWorld()
+	w.LightSource = g.NewPointLight(g.NewPoint(-10, 10, -10), g.NewColor(1, 1, 1))
+	w.Objects = []g.Shape{&floor, &wall, &ball, &glass}
+	return w
+}
+
+func goldenCamera(width int) g.Camera {
+	c := g.NewCamera(width, 16./9., math.Pi/3)
+	c.Transform = g.NewViewTransform(g.NewPoint(0, 1.5, -5), g.NewPoint(0, 1, 0), g.NewVector(0, 1, 0))
+	return c
+}
+
+func TestGoldenWhitted(t *testing.T) {
+	c := goldenCamera(64)
+	golden.Assert(t, "whitted", c.Render(checkeredRoom()), golden.NewOptions())
+}
+
+func TestGoldenPathTraced(t *testing.T) {
+	c := goldenCamera(32)
+	c.Integrator = g.PathTracer{MaxDepth: 4, RouletteDepth: 2}
+	c.SamplesPerPixel = 4
+	c.Seed = 1
+
+	post := g.PostProcess{ToneMap: g.ACESToneMap, SRGB: true}
+	golden.Assert(t, "path_traced", c.Render(checkeredRoom()).PostProcess(post), golden.NewOptions())
+}
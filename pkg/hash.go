@@ -0,0 +1,119 @@
+package goray
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"math"
+	"reflect"
+)
+
+// HashScene fingerprints everything about a world and camera that affects
+// the rendered image. Scratch state such as the rays shapes save while
+// intersecting, the world's random source and the copy of its spheres made
+// for rendering is left out, as is the file an environment map was loaded
+// from, since its image is hashed instead.
+func HashScene(w World, c Camera) [32]byte {
+	return hashOf(w, c)
+}
+
+type structField struct {
+	owner reflect.Type
+	name  string
+}
+
+// unhashedFields are left out of hashes, keyed by the struct declaring them
+// so that a field of the same name elsewhere is still hashed.
+var unhashedFields = map[structField]bool{
+	{reflect.TypeFor[Sphere](), "SavedRay"}:     true,
+	{reflect.TypeFor[Plane](), "SavedRay"}:      true,
+	{reflect.TypeFor[Rectangle](), "SavedRay"}:  true,
+	{reflect.TypeFor[World](), "Rand"}:          true,
+	{reflect.TypeFor[World](), "spheres"}:       true,
+	{reflect.TypeFor[World](), "first"}:         true,
+	{reflect.TypeFor[EnvironmentMap](), "Path"}: true,
+}
+
+// hashValue writes v into h field by field. visited numbers every pointer
+// already seen, so a second reference to the same value, such as an area
+// light's shape that is also in the world, is written as that number.
+func hashValue(h hash.Hash, v reflect.Value, visited map[uintptr]uint64) {
+	var buf [8]byte
+	writeUint := func(u uint64) {
+		binary.LittleEndian.PutUint64(buf[:], u)
+		h.Write(buf[:])
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint(1)
+		} else {
+			writeUint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint(math.Float64bits(v.Float()))
+	case reflect.String:
+		writeUint(uint64(v.Len()))
+		h.Write([]byte(v.String()))
+	case reflect.Array, reflect.Slice:
+		writeUint(uint64(v.Len()))
+		for i := range v.Len() {
+			hashValue(h, v.Index(i), visited)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if !unhashedFields[structField{v.Type(), v.Type().Field(i).Name}] {
+				hashValue(h, v.Field(i), visited)
+			}
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			writeUint(0)
+			return
+		}
+		elem := v.Elem()
+		if elem.Kind() != reflect.Pointer || visited[elem.Pointer()] == 0 {
+			h.Write([]byte(elem.Type().String()))
+		}
+		hashValue(h, elem, visited)
+	case reflect.Pointer:
+		if v.IsNil() {
+			writeUint(0)
+			return
+		}
+		if n, ok := visited[v.Pointer()]; ok {
+			writeUint(n)
+			return
+		}
+		visited[v.Pointer()] = uint64(len(visited) + 1)
+		hashValue(h, v.Elem(), visited)
+	}
+}
+
+func hashOf(values ...any) [32]byte {
+	return hashReferencing(nil, values...)
+}
+
+// hashReferencing hashes values with every pointer to one of objects
+// written as its index, so the hash depends on which objects are referred to
+// but not on what they currently look like.
+func hashReferencing(objects []Shape, values ...any) [32]byte {
+	h := sha256.New()
+	visited := map[uintptr]uint64{}
+	for i, o := range objects {
+		if v := reflect.ValueOf(o); v.Kind() == reflect.Pointer {
+			visited[v.Pointer()] = uint64(i + 1)
+		}
+	}
+	for _, v := range values {
+		hashValue(h, reflect.ValueOf(&v).Elem(), visited)
+	}
+	var sum [32]byte
+	h.Sum(sum[:0])
+	return sum
+}
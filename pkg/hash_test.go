@@ -0,0 +1,49 @@
+package goray
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashScene(t *testing.T) {
+	w, c := checkpointScene()
+	hash := HashScene(w, c)
+
+	t.Run("is stable", func(t *testing.T) {
+		w2, c2 := checkpointScene()
+		assert.Equal(t, HashScene(w2, c2), hash)
+	})
+
+	t.Run("ignores scratch state", func(t *testing.T) {
+		w2, c2 := checkpointScene()
+		w2.Rand = rand.New(rand.NewPCG(1, 1))
+		w2.Intersect(NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1)))
+		assert.Equal(t, HashScene(w2, c2), hash)
+	})
+
+	t.Run("only ignores scratch state of the types it belongs to", func(t *testing.T) {
+		a, b := NewDemoShape(), NewDemoShape()
+		b.SavedRay = NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+		assert.NotEqual(t, hashOf(&a), hashOf(&b))
+	})
+
+	t.Run("changes with the scene", func(t *testing.T) {
+		w2, c2 := checkpointScene()
+		m := w2.Objects[0].GetMaterial()
+		m.Diffuse = 0.5
+		w2.Objects[0].SetMaterial(m)
+		assert.NotEqual(t, HashScene(w2, c2), hash)
+	})
+
+	t.Run("changes with the camera", func(t *testing.T) {
+		w2, c2 := checkpointScene()
+		c2.Seed = 6
+		assert.NotEqual(t, HashScene(w2, c2), hash)
+
+		c2.Seed = 5
+		c2.Integrator = NewPathTracer()
+		assert.NotEqual(t, HashScene(w2, c2), hash)
+	})
+}
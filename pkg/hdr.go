@@ -0,0 +1,153 @@
+package goray
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// LoadHDR reads a Radiance RGBE (.hdr) image, either flat or with the
+// adaptive run-length encoded scanlines most tools write.
+func LoadHDR(r io.Reader) (Canvas, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := br.ReadString('\n')
+	if err != nil {
+		return Canvas{}, err
+	}
+	if !strings.HasPrefix(magic, "#?") {
+		return Canvas{}, errors.New("hdr: missing #? signature")
+	}
+
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return Canvas{}, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "FORMAT=") && line != "FORMAT=32-bit_rle_rgbe" {
+			return Canvas{}, fmt.Errorf("hdr: unsupported format %q", line)
+		}
+	}
+
+	resolution, err := br.ReadString('\n')
+	if err != nil {
+		return Canvas{}, err
+	}
+	var width, height int
+	if _, err := fmt.Sscanf(resolution, "-Y %d +X %d", &height, &width); err != nil {
+		return Canvas{}, fmt.Errorf("hdr: unsupported resolution line %q", strings.TrimSpace(resolution))
+	}
+	if err := checkImageSize(width, height); err != nil {
+		return Canvas{}, fmt.Errorf("hdr: %w", err)
+	}
+
+	canvas := blankCanvas(width, height)
+	scanline := make([]byte, width*4)
+	for y := range height {
+		if err := readHDRScanline(br, scanline, width); err != nil {
+			return Canvas{}, err
+		}
+		for x := range width {
+			canvas.Write(x, y, rgbeToColor(scanline[x*4:x*4+4]))
+		}
+	}
+	return canvas, nil
+}
+
+func readHDRScanline(br *bufio.Reader, scanline []byte, width int) error {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return err
+	}
+
+	if width < 8 || width > 0x7fff || header[0] != 2 || header[1] != 2 || header[2]&0x80 != 0 {
+		copy(scanline, header)
+		_, err := io.ReadFull(br, scanline[4:])
+		return err
+	}
+	if int(header[2])<<8|int(header[3]) != width {
+		return errors.New("hdr: scanline width mismatch")
+	}
+
+	for channel := range 4 {
+		for x := 0; x < width; {
+			count, err := br.ReadByte()
+			if err != nil {
+				return err
+			}
+			if count > 128 {
+				count -= 128
+				value, err := br.ReadByte()
+				if err != nil {
+					return err
+				}
+				if x+int(count) > width {
+					return errors.New("hdr: run overflows scanline")
+				}
+				for range count {
+					scanline[x*4+channel] = value
+					x++
+				}
+			} else {
+				if count == 0 || x+int(count) > width {
+					return errors.New("hdr: invalid run length")
+				}
+				for range count {
+					value, err := br.ReadByte()
+					if err != nil {
+						return err
+					}
+					scanline[x*4+channel] = value
+					x++
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func rgbeToColor(rgbe []byte) Color {
+	if rgbe[3] == 0 {
+		return Black()
+	}
+	f := math.Ldexp(1.0, int(rgbe[3])-(128+8))
+	return NewColor(
+		float64(rgbe[0])*f,
+		float64(rgbe[1])*f,
+		float64(rgbe[2])*f,
+	)
+}
+
+func colorToRGBE(c Color) [4]byte {
+	v := math.Max(c.x, math.Max(c.y, c.z))
+	if v < 1e-32 {
+		return [4]byte{}
+	}
+	mantissa, exponent := math.Frexp(v)
+	scale := mantissa * 256.0 / v
+	return [4]byte{
+		byte(math.Max(c.x, 0) * scale),
+		byte(math.Max(c.y, 0) * scale),
+		byte(math.Max(c.z, 0) * scale),
+		byte(exponent + 128),
+	}
+}
+
+func (c Canvas) WriteHDR(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y %d +X %d\n", c.Height, c.Width)
+	for _, p := range c.Pixels {
+		rgbe := colorToRGBE(p)
+		if _, err := bw.Write(rgbe[:]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
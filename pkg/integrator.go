@@ -0,0 +1,145 @@
+package goray
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+type Integrator interface {
+	Li(w World, r Ray, rng *rand.Rand) Color
+}
+
+type WhittedIntegrator struct {
+	MaxDepth int
+}
+
+func NewWhittedIntegrator() WhittedIntegrator {
+	return WhittedIntegrator{MaxDepth: 5}
+}
+
+func (wi WhittedIntegrator) Li(w World, r Ray, _ *rand.Rand) Color {
+	return w.ColorAt(r, wi.MaxDepth)
+}
+
+// PathTracer estimates global illumination by following a single random path
+// per sample. At each surface it picks a mirror, transmission or diffuse
+// bounce with probabilities given by the material's Reflective and
+// Transparency, adds emission from every surface it hits and samples the
+// world's point light and area lights directly from diffuse surfaces. An area
+// light's shape adds no emission when the bounce after such a sample hits it,
+// as the sample has already counted its light. Paths that escape pick up the
+// world's background. After RouletteDepth bounces paths are terminated by
+// Russian roulette. Fog and Volumes are ignored: paths travel through them,
+// and light reaches surfaces through them, unattenuated.
+type PathTracer struct {
+	MaxDepth      int
+	RouletteDepth int
+}
+
+func NewPathTracer() PathTracer {
+	return PathTracer{MaxDepth: 16, RouletteDepth: 3}
+}
+
+func (pt PathTracer) Li(w World, r Ray, rng *rand.Rand) Color {
+	radiance := Black()
+	throughput := White()
+	sampledLights := false
+	w.time = r.Time
+
+	for bounce := range pt.MaxDepth {
+		xs := w.Intersect(r)
+		hit, isHit := xs.Hit()
+		if !isHit {
+			w.first.record(r, nil, Computations{})
+			radiance = radiance.Add(throughput.Prod(w.BackgroundColor(r.Direction)))
+			break
+		}
+
+		comps := hit.PrepareComputations(r, xs)
+		w.first.record(r, &hit, comps)
+		w.first = nil
+		material := comps.Object.GetMaterial()
+		if !sampledLights || !w.isAreaLight(comps.Object) {
+			radiance = radiance.Add(throughput.Prod(material.Emission))
+		}
+		sampledLights = false
+
+		u := rng.Float64()
+		switch {
+		case u < material.Reflective:
+			r = NewRayAtTime(comps.OverPoint, comps.Reflectv, r.Time)
+		case u < material.Reflective+material.Transparency:
+			direction, ok := comps.RefractedDirection()
+			if ok && rng.Float64() >= comps.Schlick() {
+				r = NewRayAtTime(comps.UnderPoint, direction, r.Time)
+			} else {
+				r = NewRayAtTime(comps.OverPoint, comps.Reflectv, r.Time)
+			}
+		default:
+			albedo := PatternAtObject(material.Pattern, comps.Object, comps.Point).Mul(material.Diffuse)
+			radiance = radiance.Add(throughput.Prod(pt.directLight(w, comps, albedo, rng)))
+			sampledLights = true
+			throughput = throughput.Prod(albedo)
+			r = NewRayAtTime(comps.OverPoint, cosineSampleHemisphere(comps.Normalv, rng), r.Time)
+		}
+
+		if bounce >= pt.RouletteDepth {
+			p := math.Min(math.Max(throughput.x, math.Max(throughput.y, throughput.z)), 1.0)
+			if p <= 0.0 || rng.Float64() >= p {
+				break
+			}
+			throughput = throughput.Div(p)
+		}
+	}
+
+	return radiance
+}
+
+// directLight samples the world's point light and one point on each of its
+// area lights from a diffuse surface.
+func (pt PathTracer) directLight(w World, c Computations, albedo Color, rng *rand.Rand) Color {
+	transmittance := w.ShadowTransmittance(c.OverPoint, w.LightSource.Position)
+	color := lambert(c, w.LightSource, albedo, transmittance)
+	for _, light := range w.AreaLights {
+		if c.Object == light.Shape {
+			continue
+		}
+		sample, transmittance := w.areaLightSample(c, light, rng)
+		color = color.Add(lambert(c, sample, albedo, transmittance))
+	}
+	return color
+}
+
+// lambert is the light a diffuse surface of the given albedo reflects from
+// light, taking the light's Intensity as the irradiance it delivers face on.
+// Its BRDF is albedo/π, the same one the cosine-weighted bounce samples, so a
+// point light and a background delivering the same irradiance are equally
+// bright.
+func lambert(c Computations, light PointLight, albedo Color, transmittance float64) Color {
+	lightv := light.Position.Sub(c.Point).Normalize()
+	cos := lightv.Dot(c.Normalv)
+	if cos <= 0.0 || transmittance == 0.0 {
+		return Black()
+	}
+	return albedo.Prod(light.Intensity).Mul(cos * transmittance / math.Pi)
+}
+
+func orthonormalBasis(n Vector) (Vector, Vector) {
+	a := NewVector(1, 0, 0)
+	if math.Abs(n.x) > 0.9 {
+		a = NewVector(0, 1, 0)
+	}
+	t := n.Cross(a).Normalize()
+	return t, n.Cross(t)
+}
+
+func cosineSampleHemisphere(n Vector, rng *rand.Rand) Vector {
+	r := math.Sqrt(rng.Float64())
+	phi := 2.0 * math.Pi * rng.Float64()
+	x := r * math.Cos(phi)
+	y := r * math.Sin(phi)
+	z := math.Sqrt(math.Max(0.0, 1.0-x*x-y*y))
+
+	t, b := orthonormalBasis(n)
+	return t.Mul(x).Add(b.Mul(y)).Add(n.Mul(z)).Normalize()
+}
@@ -0,0 +1,165 @@
+package goray
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhittedIntegrator(t *testing.T) {
+	w := defaultWorld()
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+	rng := rand.New(rand.NewPCG(0, 0))
+
+	c := NewWhittedIntegrator().Li(w, r, rng)
+
+	assert.True(t, TuplesEqual(c, w.ColorAt(r, 5)))
+}
+
+func TestPathTracer(t *testing.T) {
+	t.Run("when a ray misses", func(t *testing.T) {
+		w := defaultWorld()
+		r := NewRay(NewPoint(0, 0, -5), NewVector(0, 1, 0))
+		rng := rand.New(rand.NewPCG(0, 0))
+
+		c := NewPathTracer().Li(w, r, rng)
+
+		assert.Equal(t, c, Black())
+	})
+
+	t.Run("looking directly at an emissive surface", func(t *testing.T) {
+		w := NewWorld()
+		s := NewSphere()
+		s.Material.Emission = NewColor(2, 1, 0.5)
+		s.Material.Diffuse = 0.0
+		w.Objects = []Shape{&s}
+		r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+		rng := rand.New(rand.NewPCG(0, 0))
+
+		c := NewPathTracer().Li(w, r, rng)
+
+		assert.True(t, TuplesEqual(c, NewColor(2, 1, 0.5)))
+	})
+
+	t.Run("for a diffuse surface lit by a point light", func(t *testing.T) {
+		w := NewWorld()
+		w.LightSource = NewPointLight(NewPoint(0, 10, 0), White())
+		floor := NewPlane()
+		w.Objects = []Shape{&floor}
+		r := NewRay(NewPoint(0, 1, 0), NewVector(0, -1, 0))
+		rng := rand.New(rand.NewPCG(0, 0))
+
+		c := NewPathTracer().Li(w, r, rng)
+
+		assert.True(t, TuplesEqual(c, NewColor(0.9, 0.9, 0.9).Div(math.Pi)))
+	})
+
+	t.Run("for a diffuse surface in shadow", func(t *testing.T) {
+		w := NewWorld()
+		w.LightSource = NewPointLight(NewPoint(0, 10, 0), White())
+		floor := NewPlane()
+		blocker := NewSphere()
+		blocker.SetTransform(Translation(0, 5, 0))
+		blocker.Material.Diffuse = 0.0
+		w.Objects = []Shape{&floor, &blocker}
+		r := NewRay(NewPoint(0, 1, 0), NewVector(0, -1, 0))
+		rng := rand.New(rand.NewPCG(0, 0))
+
+		c := NewPathTracer().Li(w, r, rng)
+
+		assert.True(t, TuplesEqual(c, Black()))
+	})
+
+	t.Run("through a perfect mirror", func(t *testing.T) {
+		w := NewWorld()
+		mirror := NewPlane()
+		mirror.Material.Reflective = 1.0
+		light := NewSphere()
+		light.SetTransform(Translation(0, 5, 0))
+		light.Material.Emission = White()
+		light.Material.Diffuse = 0.0
+		w.Objects = []Shape{&mirror, &light}
+		r := NewRay(NewPoint(0, 1, 0), NewVector(0, -1, 0))
+		rng := rand.New(rand.NewPCG(0, 0))
+
+		c := NewPathTracer().Li(w, r, rng)
+
+		assert.True(t, TuplesEqual(c, White()))
+	})
+
+	t.Run("for a diffuse surface under an area light", func(t *testing.T) {
+		w := NewWorld()
+		w.LightSource = NewPointLight(NewPoint(0, 10, 0), Black())
+		floor := NewPlane()
+		light := NewSphere()
+		light.SetTransform(Translation(0, 5, 0))
+		light.Material.Emission = White()
+		light.Material.Diffuse = 0.0
+		w.Objects = []Shape{&floor, &light}
+		r := NewRay(NewPoint(0, 1, 0), NewVector(0, -1, 0))
+		rng := rand.New(rand.NewPCG(0, 0))
+		direct := PathTracer{MaxDepth: 1}
+
+		lit := func(w World) Color {
+			sum := Black()
+			for range 1000 {
+				sum = sum.Add(direct.Li(w, r, rng))
+			}
+			return sum.Div(1000)
+		}
+		assert.Equal(t, lit(w), Black())
+
+		w.AreaLights = []AreaLight{NewAreaLight(&light, 1)}
+		c := lit(w)
+		assert.InDelta(t, c.x, 0.9*0.4/math.Pi, 0.01)
+	})
+}
+
+// A surface lit by a uniform background of radiance 1 receives an irradiance
+// of π, all of it through indirect bounces. A point light of intensity π
+// delivers the same irradiance directly, and must light it just as brightly.
+func TestPathTracerFurnace(t *testing.T) {
+	m := NewMaterial()
+	m.Diffuse = 0.5
+	m.Specular = 0.0
+	floor := NewPlane()
+	floor.Material = m
+	r := NewRay(NewPoint(0, 1, 0), NewVector(0, -1, 0))
+	pt := PathTracer{MaxDepth: 2, RouletteDepth: 2}
+
+	t.Run("under a uniform background", func(t *testing.T) {
+		w := NewWorld()
+		w.LightSource = NewPointLight(NewPoint(0, 10, 0), Black())
+		w.Background = NewConstantBackground(White())
+		w.Objects = []Shape{&floor}
+		rng := rand.New(rand.NewPCG(0, 0))
+
+		c := pt.Li(w, r, rng)
+
+		assert.True(t, TuplesEqual(c, NewColor(0.5, 0.5, 0.5)))
+	})
+
+	t.Run("under a point light", func(t *testing.T) {
+		w := NewWorld()
+		w.LightSource = NewPointLight(NewPoint(0, 10, 0), White().Mul(math.Pi))
+		w.Objects = []Shape{&floor}
+		rng := rand.New(rand.NewPCG(0, 0))
+
+		c := pt.Li(w, r, rng)
+
+		assert.True(t, TuplesEqual(c, NewColor(0.5, 0.5, 0.5)))
+	})
+}
+
+func TestCosineSampleHemisphere(t *testing.T) {
+	n := NewVector(0, 0, 1)
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	for range 100 {
+		v := cosineSampleHemisphere(n, rng)
+		assert.InDelta(t, v.Magnitude(), 1.0, 0.00001)
+		assert.GreaterOrEqual(t, v.Dot(n), 0.0)
+	}
+}
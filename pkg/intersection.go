@@ -21,6 +21,7 @@ type Computations struct {
 	Eyev, Normalv, Reflectv Vector
 	Inside                  bool
 	N1, N2                  float64
+	Time                    float64
 }
 
 func NewIntersection(t float64, s Shape) Intersection {
@@ -30,7 +31,7 @@ func NewIntersection(t float64, s Shape) Intersection {
 func (i Intersection) PrepareComputations(ray Ray, xs Intersections) Computations {
 	point := ray.At(i.T)
 	eyev := ray.Direction.Neg()
-	normalv := NormalAt(i.Object, point)
+	normalv := NormalAtTime(i.Object, point, ray.Time)
 	reflectv := ray.Direction.Reflect(normalv)
 	inside := false
 
@@ -74,13 +75,14 @@ func (i Intersection) PrepareComputations(ray Ray, xs Intersections) Computation
 		T:          i.T,
 		Point:      point,
 		OverPoint:  point.Add(normalv.Mul(0.00001)),
-		UnderPoint: point.Sub(normalv.Mul(0.00001)),
+		UnderPoint: point.Add(normalv.Mul(-0.00001)),
 		Eyev:       eyev,
 		Normalv:    normalv,
 		Reflectv:   reflectv,
 		Inside:     inside,
 		N1:         n1,
 		N2:         n2,
+		Time:       ray.Time,
 	}
 }
 
@@ -113,3 +115,17 @@ func (c Computations) Schlick() float64 {
 	r0 := math.Pow((c.N1-c.N2)/(c.N1+c.N2), 2)
 	return r0 + (1-r0)*math.Pow(1-cos, 5)
 }
+
+func (c Computations) RefractedDirection() (Vector, bool) {
+	nRatio := c.N1 / c.N2
+	cosI := c.Eyev.Dot(c.Normalv)
+
+	sin2T := nRatio * nRatio * (1 - cosI*cosI)
+
+	if sin2T > 1.0 {
+		return Vector{}, false
+	}
+
+	cosT := math.Sqrt(1.0 - sin2T)
+	return c.Normalv.Mul(nRatio*cosI - cosT).Sub(c.Eyev.Mul(nRatio)), true
+}
@@ -8,3 +8,30 @@ type PointLight struct {
 func NewPointLight(position Point, intensity Color) PointLight {
 	return PointLight{Position: position, Intensity: intensity}
 }
+
+type SurfaceSampler interface {
+	Shape
+	SampleSurface(u, v float64) Point
+}
+
+// AreaLight turns an emissive shape into a light source. Each shading point
+// treats Samples random points on the shape's surface as point lights with
+// the shape's Emission, weighted by their visibility, and averages them.
+// Only their diffuse and specular light is counted; ambient light comes from
+// the world's LightSource alone.
+type AreaLight struct {
+	Shape   SurfaceSampler
+	Samples int
+}
+
+func NewAreaLight(shape SurfaceSampler, samples int) AreaLight {
+	return AreaLight{Shape: shape, Samples: samples}
+}
+
+func (al AreaLight) Intensity() Color {
+	return al.Shape.GetMaterial().Emission
+}
+
+func (al AreaLight) SamplePoint(u, v float64) Point {
+	return al.Shape.GetTransform().MulPoint(al.Shape.SampleSurface(u, v))
+}
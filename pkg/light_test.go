@@ -15,3 +15,15 @@ func TestPointLight(t *testing.T) {
 	assert.True(t, TuplesEqual(light.Position, position))
 	assert.True(t, TuplesEqual(light.Intensity, intensity))
 }
+
+func TestAreaLight(t *testing.T) {
+	s := NewSphere()
+	s.Material.Emission = NewColor(1, 0.5, 0)
+	s.SetTransform(Translation(0, 5, 0))
+
+	light := NewAreaLight(&s, 4)
+
+	assert.Equal(t, light.Samples, 4)
+	assert.True(t, TuplesEqual(light.Intensity(), NewColor(1, 0.5, 0)))
+	assert.True(t, TuplesEqual(light.SamplePoint(0, 0), NewPoint(0, 5, 1)))
+}
@@ -0,0 +1,68 @@
+package goray
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Limits bounds how much work a scene may ask for, for services that render
+// documents sent by clients. MaxWidth and MaxHeight bound the image size,
+// MaxSamples every sample count: per pixel, per area light, for image-based
+// lighting, per glossy bounce and per volume. MaxDepth bounds the
+// integrator's recursion, which for the Whitted integrator can double the
+// rays traced at every level.
+type Limits struct {
+	MaxWidth, MaxHeight int
+	MaxSamples          int
+	MaxDepth            int
+}
+
+func NewLimits() Limits {
+	return Limits{MaxWidth: 4096, MaxHeight: 4096, MaxSamples: 1024, MaxDepth: 16}
+}
+
+// Check reports the first way w and c ask for more work than l allows.
+func (l Limits) Check(w World, c Camera) error {
+	if c.Width > l.MaxWidth || c.Height > l.MaxHeight {
+		return fmt.Errorf("image is %dx%d, larger than the %dx%d allowed", c.Width, c.Height, l.MaxWidth, l.MaxHeight)
+	}
+
+	var depths map[string]int
+	switch integrator := c.Integrator.(type) {
+	case WhittedIntegrator:
+		depths = map[string]int{"max depth": integrator.MaxDepth}
+	case PathTracer:
+		depths = map[string]int{"max depth": integrator.MaxDepth, "roulette depth": integrator.RouletteDepth}
+	case nil:
+	default:
+		return fmt.Errorf("integrator %T is not allowed", integrator)
+	}
+	if err := checkCounts(depths, l.MaxDepth); err != nil {
+		return err
+	}
+
+	samples := map[string]int{
+		"camera samples":      c.SamplesPerPixel,
+		"image-based samples": w.IBLSamples,
+	}
+	for i, light := range w.AreaLights {
+		samples[fmt.Sprintf("area light %d samples", i)] = light.Samples
+	}
+	for i, object := range w.Objects {
+		samples[fmt.Sprintf("object %d glossy samples", i)] = object.GetMaterial().GlossySamples
+	}
+	for i, volume := range w.Volumes {
+		samples[fmt.Sprintf("volume %d steps", i)] = volume.Steps
+	}
+	return checkCounts(samples, l.MaxSamples)
+}
+
+func checkCounts(counts map[string]int, limit int) error {
+	for _, name := range slices.Sorted(maps.Keys(counts)) {
+		if counts[name] > limit {
+			return fmt.Errorf("%s is %d, more than the %d allowed", name, counts[name], limit)
+		}
+	}
+	return nil
+}
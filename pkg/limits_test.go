@@ -0,0 +1,35 @@
+package goray
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits(t *testing.T) {
+	l := NewLimits()
+	w := defaultWorld()
+	c := NewCamera(100, 1, math.Pi/2)
+	assert.NoError(t, l.Check(w, c))
+
+	t.Run("bounds the image size", func(t *testing.T) {
+		assert.ErrorContains(t, l.Check(w, NewCamera(100, 0.01, math.Pi/2)), "larger than")
+	})
+
+	t.Run("bounds the integrator's depth", func(t *testing.T) {
+		c := c
+		c.Integrator = WhittedIntegrator{MaxDepth: 60}
+		assert.ErrorContains(t, l.Check(w, c), "max depth is 60")
+		c.Integrator = PathTracer{MaxDepth: 16, RouletteDepth: 1 << 20}
+		assert.ErrorContains(t, l.Check(w, c), "roulette depth")
+	})
+
+	t.Run("bounds sample counts", func(t *testing.T) {
+		w := defaultWorld()
+		m := w.Objects[1].GetMaterial()
+		m.GlossySamples = 1 << 20
+		w.Objects[1].SetMaterial(m)
+		assert.ErrorContains(t, l.Check(w, c), "object 1 glossy samples")
+	})
+}
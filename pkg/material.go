@@ -2,11 +2,27 @@ package goray
 
 import "math"
 
+type ShadingModel int
+
+const (
+	PhongShading ShadingModel = iota
+	MicrofacetShading
+)
+
 type Material struct {
+	Model                         ShadingModel
 	Ambient, Diffuse, Specular    float64
 	Shininess, Reflective         float64
 	Transparency, RefractiveIndex float64
-	Pattern                       Pattern
+	// Roughness shapes the microfacet highlight and also blurs reflections
+	// and refractions, under either model, so a rough metal is rough in both.
+	// Phong highlights come from Shininess alone, so a Phong material can set
+	// Roughness just to blur what it reflects.
+	Metallic, Roughness float64
+	GlossySamples       int
+	CastsShadow         bool
+	Emission            Color
+	Pattern             Pattern
 }
 
 func NewMaterial() Material {
@@ -20,14 +36,38 @@ func NewMaterial() Material {
 		Transparency:    0.0,
 		RefractiveIndex: 1.0,
 		Reflective:      0.0,
+		GlossySamples:   8,
+		CastsShadow:     true,
+		Emission:        Black(),
 	}
 }
 
-func (m Material) Lighting(s Shape, light PointLight, point Point, eyev, normalv Vector, inShadow bool) Color {
+func NewPBRMaterial(baseColor Color, metallic, roughness, ior float64) Material {
+	m := NewMaterial()
+	pattern := NewSolidPattern(baseColor)
+	m.Model = MicrofacetShading
+	m.Pattern = &pattern
+	m.Metallic = metallic
+	m.Roughness = roughness
+	m.RefractiveIndex = ior
+	return m
+}
+
+func (m Material) Lighting(s Shape, light PointLight, point Point, eyev, normalv Vector, lightIntensity float64) Color {
+	ambient := PatternAtObject(m.Pattern, s, point).Prod(light.Intensity).Mul(m.Ambient)
+	return ambient.Add(m.directLighting(s, light, point, eyev, normalv).Mul(lightIntensity))
+}
+
+// directLighting is the diffuse and specular light an unoccluded light casts
+// on point, without the ambient term.
+func (m Material) directLighting(s Shape, light PointLight, point Point, eyev, normalv Vector) Color {
+	if m.Model == MicrofacetShading {
+		return m.microfacetLighting(s, light, point, eyev, normalv)
+	}
+
 	effectiveColor := PatternAtObject(m.Pattern, s, point).Prod(light.Intensity)
 	lightv := light.Position.Sub(point).Normalize()
 
-	ambient := effectiveColor.Mul(m.Ambient)
 	diffuse := NewColor(0, 0, 0)
 	specular := NewColor(0, 0, 0)
 
@@ -45,8 +85,5 @@ func (m Material) Lighting(s Shape, light PointLight, point Point, eyev, normalv
 		}
 	}
 
-	if inShadow {
-		return ambient
-	}
-	return ambient.Add(diffuse).Add(specular)
+	return diffuse.Add(specular)
 }
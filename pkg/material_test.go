@@ -20,14 +20,30 @@ func TestNewMaterial(t *testing.T) {
 	assert.Equal(t, m.Transparency, 0.0)
 	assert.Equal(t, m.RefractiveIndex, 1.0)
 	assert.Equal(t, m.Reflective, 0.0)
+	assert.True(t, m.CastsShadow)
+	assert.Equal(t, m.Emission, Black())
+	assert.Equal(t, m.Model, PhongShading)
+	assert.Equal(t, m.Roughness, 0.0)
+	assert.Equal(t, m.GlossySamples, 8)
+}
+
+func TestNewPBRMaterial(t *testing.T) {
+	m := NewPBRMaterial(NewColor(1, 0.5, 0.25), 1.0, 0.3, 1.45)
+
+	pattern := NewSolidPattern(NewColor(1, 0.5, 0.25))
+	assert.Equal(t, m.Model, MicrofacetShading)
+	assert.Equal(t, m.Pattern, &pattern)
+	assert.Equal(t, m.Metallic, 1.0)
+	assert.Equal(t, m.Roughness, 0.3)
+	assert.Equal(t, m.RefractiveIndex, 1.45)
 }
 
 type LightingTestCase struct {
-	description   string
-	eyev, normalv Vector
-	light         PointLight
-	result        Color
-	inShadow      bool
+	description    string
+	eyev, normalv  Vector
+	light          PointLight
+	result         Color
+	lightIntensity float64
 }
 
 func TestLighting(t *testing.T) {
@@ -37,58 +53,66 @@ func TestLighting(t *testing.T) {
 
 	testCases := []LightingTestCase{
 		LightingTestCase{
-			description: "with the eye between the light and surface",
-			eyev:        NewVector(0, 0, -1),
-			normalv:     NewVector(0, 0, -1),
-			light:       NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1)),
-			inShadow:    false,
-			result:      NewColor(1.9, 1.9, 1.9),
+			description:    "with the eye between the light and surface",
+			eyev:           NewVector(0, 0, -1),
+			normalv:        NewVector(0, 0, -1),
+			light:          NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1)),
+			lightIntensity: 1.0,
+			result:         NewColor(1.9, 1.9, 1.9),
+		},
+		LightingTestCase{
+			description:    "with the eye between the light and surface. eye offset 45 degrees",
+			eyev:           NewVector(0, math.Sqrt2/2, -math.Sqrt2/2),
+			normalv:        NewVector(0, 0, -1),
+			light:          NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1)),
+			lightIntensity: 1.0,
+			result:         NewColor(1.0, 1.0, 1.0),
 		},
 		LightingTestCase{
-			description: "with the eye between the light and surface. eye offset 45 degrees",
-			eyev:        NewVector(0, math.Sqrt2/2, -math.Sqrt2/2),
-			normalv:     NewVector(0, 0, -1),
-			light:       NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1)),
-			inShadow:    false,
-			result:      NewColor(1.0, 1.0, 1.0),
+			description:    "with the eye opposite surface, light offset 45 degrees",
+			eyev:           NewVector(0, 0, -1),
+			normalv:        NewVector(0, 0, -1),
+			light:          NewPointLight(NewPoint(0, 10, -10), NewColor(1, 1, 1)),
+			lightIntensity: 1.0,
+			result:         NewColor(0.7364, 0.7364, 0.7364),
 		},
 		LightingTestCase{
-			description: "with the eye opposite surface, light offset 45 degrees",
-			eyev:        NewVector(0, 0, -1),
-			normalv:     NewVector(0, 0, -1),
-			light:       NewPointLight(NewPoint(0, 10, -10), NewColor(1, 1, 1)),
-			inShadow:    false,
-			result:      NewColor(0.7364, 0.7364, 0.7364),
+			description:    "with the eye in the path of the reflection vector",
+			eyev:           NewVector(0, -math.Sqrt2/2, -math.Sqrt2/2),
+			normalv:        NewVector(0, 0, -1),
+			light:          NewPointLight(NewPoint(0, 10, -10), NewColor(1, 1, 1)),
+			lightIntensity: 1.0,
+			result:         NewColor(1.6364, 1.6364, 1.6364),
 		},
 		LightingTestCase{
-			description: "with the eye in the path of the reflection vector",
-			eyev:        NewVector(0, -math.Sqrt2/2, -math.Sqrt2/2),
-			normalv:     NewVector(0, 0, -1),
-			light:       NewPointLight(NewPoint(0, 10, -10), NewColor(1, 1, 1)),
-			inShadow:    false,
-			result:      NewColor(1.6364, 1.6364, 1.6364),
+			description:    "with the light behind the surface",
+			eyev:           NewVector(0, 0, -1),
+			normalv:        NewVector(0, 0, -1),
+			light:          NewPointLight(NewPoint(0, 10, 10), NewColor(1, 1, 1)),
+			lightIntensity: 1.0,
+			result:         NewColor(0.1, 0.1, 0.1),
 		},
 		LightingTestCase{
-			description: "with the light behind the surface",
-			eyev:        NewVector(0, 0, -1),
-			normalv:     NewVector(0, 0, -1),
-			light:       NewPointLight(NewPoint(0, 10, 10), NewColor(1, 1, 1)),
-			inShadow:    false,
-			result:      NewColor(0.1, 0.1, 0.1),
+			description:    "with the surface in shadow",
+			eyev:           NewVector(0, 0, -1),
+			normalv:        NewVector(0, 0, -1),
+			light:          NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1)),
+			lightIntensity: 0.0,
+			result:         NewColor(0.1, 0.1, 0.1),
 		},
 		LightingTestCase{
-			description: "with the surface in shadow",
-			eyev:        NewVector(0, 0, -1),
-			normalv:     NewVector(0, 0, -1),
-			light:       NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1)),
-			inShadow:    true,
-			result:      NewColor(0.1, 0.1, 0.1),
+			description:    "with the surface partially shadowed",
+			eyev:           NewVector(0, 0, -1),
+			normalv:        NewVector(0, 0, -1),
+			light:          NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1)),
+			lightIntensity: 0.5,
+			result:         NewColor(1.0, 1.0, 1.0),
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
-			actual := m.Lighting(&s, tc.light, position, tc.eyev, tc.normalv, tc.inShadow)
+			actual := m.Lighting(&s, tc.light, position, tc.eyev, tc.normalv, tc.lightIntensity)
 			assert.True(t, TuplesEqual(tc.result, actual))
 		})
 	}
@@ -108,8 +132,8 @@ func TestLighting(t *testing.T) {
 
 		light := NewPointLight(NewPoint(0, 0, -10), NewColor(1, 1, 1))
 
-		c1 := m.Lighting(&s, light, NewPoint(0.9, 0, 0), eyev, normalv, false)
-		c2 := m.Lighting(&s, light, NewPoint(1.1, 0, 0), eyev, normalv, false)
+		c1 := m.Lighting(&s, light, NewPoint(0.9, 0, 0), eyev, normalv, 1.0)
+		c2 := m.Lighting(&s, light, NewPoint(1.1, 0, 0), eyev, normalv, 1.0)
 
 		assert.Equal(t, c1, White())
 		assert.Equal(t, c2, Black())
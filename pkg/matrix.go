@@ -19,37 +19,56 @@ func (m Matrix) At(row, col int) float64 {
 }
 
 func (m Matrix) Mul(n Matrix) Matrix {
-	r := make([]float64, 16)
+	var r Matrix
 	for row := range 4 {
 		for col := range 4 {
 			val := 0.0
 			for i := range 4 {
-				val += m.At(row, i) * n.At(i, col)
+				val += m[row*4+i] * n[i*4+col]
 			}
 			r[row*4+col] = val
 		}
 	}
-	return Matrix(r)
+	return r
 }
 
 func (m Matrix) Mult(t Tuple) Tuple {
-	r := make([]float64, 4)
-	for i := range 4 {
-		s := i * 4
-		t2 := NewTuple(m[s], m[s+1], m[s+2], m[s+3])
-		r[i] = t.Dot(t2)
+	return NewTuple(
+		t.x*m[0]+t.y*m[1]+t.z*m[2]+t.w*m[3],
+		t.x*m[4]+t.y*m[5]+t.z*m[6]+t.w*m[7],
+		t.x*m[8]+t.y*m[9]+t.z*m[10]+t.w*m[11],
+		t.x*m[12]+t.y*m[13]+t.z*m[14]+t.w*m[15],
+	)
+}
+
+func (m Matrix) MulPoint(p Point) Point {
+	return Point(m.Mult(Tuple(p)))
+}
+
+// MulVector transforms v, ignoring the matrix's translation and bottom row.
+// Applied with the inverse transpose of a shape's transform it carries the
+// shape's normals into world space.
+func (m Matrix) MulVector(v Vector) Vector {
+	t := m.Mult(Tuple(v))
+	return NewVector(t.x, t.y, t.z)
+}
+
+func (m Matrix) Lerp(n Matrix, t float64) Matrix {
+	var r Matrix
+	for i := range r {
+		r[i] = m[i] + (n[i]-m[i])*t
 	}
-	return NewTuple(r[0], r[1], r[2], r[3])
+	return r
 }
 
 func (m Matrix) Transpose() Matrix {
-	r := make([]float64, 16)
+	var r Matrix
 	for row := range 4 {
 		for col := range 4 {
-			r[col*4+row] = m.At(row, col)
+			r[col*4+row] = m[row*4+col]
 		}
 	}
-	return Matrix(r)
+	return r
 }
 
 func (m Matrix) Submatrix(xrow, xcol int) Matrix3x3 {
@@ -76,27 +95,131 @@ func (m Matrix) Cofactor(row, col int) float64 {
 	return minor
 }
 
+// Determinant expands m along its 2x2 minors rather than through
+// Submatrix, so it allocates nothing.
 func (m Matrix) Determinant() float64 {
-	r := 0.0
-	for col := range 4 {
-		r += m.At(0, col) * m.Cofactor(0, col)
+	s, c := m.minors2x2()
+	return s[0]*c[5] - s[1]*c[4] + s[2]*c[3] + s[3]*c[2] - s[4]*c[1] + s[5]*c[0]
+}
+
+// minors2x2 returns the determinants of the six 2x2 matrices that can be
+// made from the top two rows, and the six from the bottom two rows, ordered
+// so that s[i] pairs with c[5-i] in the Laplace expansion.
+func (m Matrix) minors2x2() (s, c [6]float64) {
+	s = [6]float64{
+		m[0]*m[5] - m[4]*m[1],
+		m[0]*m[6] - m[4]*m[2],
+		m[0]*m[7] - m[4]*m[3],
+		m[1]*m[6] - m[5]*m[2],
+		m[1]*m[7] - m[5]*m[3],
+		m[2]*m[7] - m[6]*m[3],
 	}
-	return r
+	c = [6]float64{
+		m[8]*m[13] - m[12]*m[9],
+		m[8]*m[14] - m[12]*m[10],
+		m[8]*m[15] - m[12]*m[11],
+		m[9]*m[14] - m[13]*m[10],
+		m[9]*m[15] - m[13]*m[11],
+		m[10]*m[15] - m[14]*m[11],
+	}
+	return s, c
 }
 
 func (m Matrix) IsInvertible() bool {
 	return m.Determinant() != 0.0
 }
 
+// IsAffine reports whether m's bottom row is 0, 0, 0, 1, as it is for every
+// combination of translations, rotations, scalings and shearings.
+func (m Matrix) IsAffine() bool {
+	return m[12] == 0 && m[13] == 0 && m[14] == 0 && m[15] == 1
+}
+
+// Inverse panics if m is singular. Use TryInverse for matrices that may be.
 func (m Matrix) Inverse() Matrix {
-	if !m.IsInvertible() {
+	inverse, ok := m.TryInverse()
+	if !ok {
 		panic(m)
 	}
-	r := make([]float64, 16)
-	for row := range 4 {
-		for col := range 4 {
-			r[col*4+row] = m.Cofactor(row, col) / m.Determinant()
-		}
+	return inverse
+}
+
+// TryInverse returns the inverse of m, or false if m is singular. Affine
+// matrices only need their upper 3x3 inverted; anything else is inverted
+// in closed form from its 2x2 minors.
+func (m Matrix) TryInverse() (Matrix, bool) {
+	if m.IsAffine() {
+		return m.affineInverse()
+	}
+
+	s, c := m.minors2x2()
+	det := s[0]*c[5] - s[1]*c[4] + s[2]*c[3] + s[3]*c[2] - s[4]*c[1] + s[5]*c[0]
+	if det == 0.0 {
+		return Matrix{}, false
+	}
+
+	r := Matrix{
+		m[5]*c[5] - m[6]*c[4] + m[7]*c[3],
+		-m[1]*c[5] + m[2]*c[4] - m[3]*c[3],
+		m[13]*s[5] - m[14]*s[4] + m[15]*s[3],
+		-m[9]*s[5] + m[10]*s[4] - m[11]*s[3],
+
+		-m[4]*c[5] + m[6]*c[2] - m[7]*c[1],
+		m[0]*c[5] - m[2]*c[2] + m[3]*c[1],
+		-m[12]*s[5] + m[14]*s[2] - m[15]*s[1],
+		m[8]*s[5] - m[10]*s[2] + m[11]*s[1],
+
+		m[4]*c[4] - m[5]*c[2] + m[7]*c[0],
+		-m[0]*c[4] + m[1]*c[2] - m[3]*c[0],
+		m[12]*s[4] - m[13]*s[2] + m[15]*s[0],
+		-m[8]*s[4] + m[9]*s[2] - m[11]*s[0],
+
+		-m[4]*c[3] + m[5]*c[1] - m[6]*c[0],
+		m[0]*c[3] - m[1]*c[1] + m[2]*c[0],
+		-m[12]*s[3] + m[13]*s[1] - m[14]*s[0],
+		m[8]*s[3] - m[9]*s[1] + m[10]*s[0],
+	}
+	for i := range r {
+		r[i] /= det
 	}
-	return Matrix(r)
+	return r, true
+}
+
+// affineInverse inverts the upper 3x3 of m by its adjugate. The translation
+// column comes from the same 3x3 minors a cofactor expansion of the whole
+// matrix would use, rather than from the inverted 3x3, so it rounds exactly
+// as the cofactor inverse does and renders are unchanged by the fast path.
+func (m Matrix) affineInverse() (Matrix, bool) {
+	c00 := m[5]*m[10] - m[6]*m[9]
+	c01 := m[6]*m[8] - m[4]*m[10]
+	c02 := m[4]*m[9] - m[5]*m[8]
+	det := m[0]*c00 + m[1]*c01 + m[2]*c02
+	if det == 0.0 {
+		return Matrix{}, false
+	}
+
+	a := [9]float64{
+		c00, m[2]*m[9] - m[1]*m[10], m[1]*m[6] - m[2]*m[5],
+		c01, m[0]*m[10] - m[2]*m[8], m[2]*m[4] - m[0]*m[6],
+		c02, m[1]*m[8] - m[0]*m[9], m[0]*m[5] - m[1]*m[4],
+	}
+	for i := range a {
+		a[i] /= det
+	}
+
+	tx := -det3(m[1], m[2], m[3], m[5], m[6], m[7], m[9], m[10], m[11])
+	ty := det3(m[0], m[2], m[3], m[4], m[6], m[7], m[8], m[10], m[11])
+	tz := -det3(m[0], m[1], m[3], m[4], m[5], m[7], m[8], m[9], m[11])
+	return Matrix{
+		a[0], a[1], a[2], tx / det,
+		a[3], a[4], a[5], ty / det,
+		a[6], a[7], a[8], tz / det,
+		0, 0, 0, 1,
+	}, true
+}
+
+// det3 expands the 3x3 determinant along its first row, in the same order
+// as Matrix3x3.Determinant.
+func det3(a, b, c, d, e, f, g, h, i float64) float64 {
+	return a*(e*i-f*h) - b*(d*i-f*g) + c*(d*h-e*g)
 }
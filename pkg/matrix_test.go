@@ -2,6 +2,7 @@ package goray
 
 import (
 	"math"
+	"math/rand/v2"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -182,3 +183,146 @@ func TestMatrixInverse(t *testing.T) {
 		assert.True(t, MatricesEqual(c.Mul(b.Inverse()), a))
 	})
 }
+
+// cofactorInverse is the textbook inverse through 3x3 cofactors, kept as a
+// reference for the closed-form one.
+// cofactorInverse inverts m the way Inverse did before it was written in
+// closed form, expanding every determinant through Submatrix.
+func cofactorInverse(m Matrix) Matrix {
+	var r Matrix
+	det := 0.0
+	for col := range 4 {
+		det += m.At(0, col) * m.Cofactor(0, col)
+	}
+	for row := range 4 {
+		for col := range 4 {
+			r[col*4+row] = m.Cofactor(row, col) / det
+		}
+	}
+	return r
+}
+
+func randomMatrix(rng *rand.Rand) Matrix {
+	var m Matrix
+	for i := range m {
+		m[i] = rng.Float64()*20 - 10
+	}
+	return m
+}
+
+func randomTransform(rng *rand.Rand) Matrix {
+	return Translation(rng.Float64()*10, rng.Float64()*10, rng.Float64()*10).
+		Mul(RotationY(rng.Float64() * math.Pi)).
+		Mul(RotationX(rng.Float64() * math.Pi)).
+		Mul(Shearing(rng.Float64(), 0, 0, rng.Float64(), 0, 0)).
+		Mul(Scaling(rng.Float64()+0.1, rng.Float64()+0.1, rng.Float64()+0.1))
+}
+
+func TestClosedFormInverse(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	t.Run("matches the cofactor inverse", func(t *testing.T) {
+		for range 100 {
+			m := randomMatrix(rng)
+			assert.True(t, MatricesEqual(m.Inverse(), cofactorInverse(m)))
+		}
+	})
+
+	t.Run("matches the cofactor inverse for affine transforms", func(t *testing.T) {
+		for range 100 {
+			m := randomTransform(rng)
+			assert.True(t, m.IsAffine())
+			// Exactly, so the fast path does not change what a scene renders.
+			inverse, expected := m.Inverse(), cofactorInverse(m)
+			for i := range inverse {
+				assert.True(t, inverse[i] == expected[i], "entry %d: %v != %v", i, inverse[i], expected[i])
+			}
+			assert.True(t, MatricesEqual(inverse.Mul(m), IdentityMatrix()))
+		}
+	})
+
+	t.Run("does not allocate", func(t *testing.T) {
+		general, affine := randomMatrix(rng), randomTransform(rng)
+		allocs := testing.AllocsPerRun(100, func() {
+			general.Inverse()
+			affine.Inverse()
+		})
+		assert.Equal(t, allocs, 0.0)
+	})
+}
+
+func TestTryInverse(t *testing.T) {
+	t.Run("of an invertible matrix", func(t *testing.T) {
+		a := NewMatrix(8, -5, 9, 2, 7, 5, 6, 1, -6, 0, 9, 6, -3, 0, -9, -4)
+		b, ok := a.TryInverse()
+		assert.True(t, ok)
+		assert.Equal(t, b, a.Inverse())
+	})
+
+	t.Run("of a singular matrix", func(t *testing.T) {
+		a := NewMatrix(-4, 2, -2, -3, 9, 6, 2, 6, 0, -5, 1, -5, 0, 0, 0, 0)
+		_, ok := a.TryInverse()
+		assert.False(t, ok)
+		assert.Panics(t, func() { a.Inverse() })
+	})
+
+	t.Run("of a singular affine matrix", func(t *testing.T) {
+		_, ok := Translation(1, 2, 3).Mul(Scaling(1, 0, 1)).TryInverse()
+		assert.False(t, ok)
+	})
+}
+
+func BenchmarkInverse(b *testing.B) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	general, affine := randomMatrix(rng), randomTransform(rng)
+
+	b.Run("cofactor", func(b *testing.B) {
+		for range b.N {
+			cofactorInverse(general)
+		}
+	})
+	b.Run("closed form", func(b *testing.B) {
+		for range b.N {
+			general.Inverse()
+		}
+	})
+	b.Run("affine", func(b *testing.B) {
+		for range b.N {
+			affine.Inverse()
+		}
+	})
+}
+
+func TestMultiplyingPointsAndVectors(t *testing.T) {
+	m := Translation(5, -3, 2).Mul(Scaling(2, 2, 2))
+
+	assert.Equal(t, m.MulPoint(NewPoint(1, 1, 1)), NewPoint(7, -1, 4))
+	assert.Equal(t, m.MulVector(NewVector(1, 1, 1)), NewVector(2, 2, 2))
+
+	t.Run("the inverse transpose carries normals with a w of 0", func(t *testing.T) {
+		n := m.Inverse().Transpose().MulVector(NewVector(0, 1, 0))
+		assert.True(t, TuplesEqual(n, NewVector(0, 0.5, 0)))
+		assert.Equal(t, n.w, 0.0)
+	})
+}
+
+func BenchmarkMultiply(b *testing.B) {
+	m := randomTransform(rand.New(rand.NewPCG(1, 2)))
+	n := randomTransform(rand.New(rand.NewPCG(3, 4)))
+	p := NewPoint(1, 2, 3)
+
+	b.Run("matrices", func(b *testing.B) {
+		var r Matrix
+		for range b.N {
+			r = m.Mul(n)
+		}
+		_ = r
+	})
+	b.Run("points", func(b *testing.B) {
+		var r Point
+		for range b.N {
+			r = m.MulPoint(p)
+		}
+		_ = r
+	})
+}
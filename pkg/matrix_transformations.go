@@ -55,3 +55,57 @@ func Shearing(xy, xz, yx, yz, zx, zy float64) Matrix {
 	m.Write(2, 1, zy)
 	return m
 }
+
+// TransformBuilder chains transformations in the order they are applied,
+// so NewTransformBuilder().Scale(2, 2, 2).Translate(0, 1, 0).Matrix() scales
+// first and then translates, the same as
+// Translation(0, 1, 0).Mul(Scaling(2, 2, 2)). Each call returns a new
+// builder, so a partial chain can be shared.
+type TransformBuilder struct {
+	m Matrix
+}
+
+func NewTransformBuilder() TransformBuilder {
+	return TransformBuilder{m: IdentityMatrix()}
+}
+
+// Then applies m after everything chained so far.
+func (b TransformBuilder) Then(m Matrix) TransformBuilder {
+	return TransformBuilder{m: m.Mul(b.m)}
+}
+
+func (b TransformBuilder) Translate(x, y, z float64) TransformBuilder {
+	return b.Then(Translation(x, y, z))
+}
+
+func (b TransformBuilder) Scale(x, y, z float64) TransformBuilder {
+	return b.Then(Scaling(x, y, z))
+}
+
+func (b TransformBuilder) RotateX(radians float64) TransformBuilder {
+	return b.Then(RotationX(radians))
+}
+
+func (b TransformBuilder) RotateY(radians float64) TransformBuilder {
+	return b.Then(RotationY(radians))
+}
+
+func (b TransformBuilder) RotateZ(radians float64) TransformBuilder {
+	return b.Then(RotationZ(radians))
+}
+
+func (b TransformBuilder) Rotate(q Quaternion) TransformBuilder {
+	return b.Then(q.Matrix())
+}
+
+func (b TransformBuilder) RotateAbout(axis Vector, radians float64) TransformBuilder {
+	return b.Rotate(AxisAngle(axis, radians))
+}
+
+func (b TransformBuilder) Shear(xy, xz, yx, yz, zx, zy float64) TransformBuilder {
+	return b.Then(Shearing(xy, xz, yx, yz, zx, zy))
+}
+
+func (b TransformBuilder) Matrix() Matrix {
+	return b.m
+}
@@ -17,123 +17,123 @@ type TransformTestCase struct {
 func TestMatrixTransformations(t *testing.T) {
 	testCases := []TransformTestCase{
 		TransformTestCase{
-			original:    NewPoint(-3, 4, 5),
-			result:      NewPoint(2, 1, 7),
+			original:    Tuple(NewPoint(-3, 4, 5)),
+			result:      Tuple(NewPoint(2, 1, 7)),
 			transform:   Translation(5, -3, 2),
 			description: "multiplying by a translation matrix",
 		},
 		TransformTestCase{
-			original:    NewPoint(-3, 4, 5),
-			result:      NewPoint(-8, 7, 3),
+			original:    Tuple(NewPoint(-3, 4, 5)),
+			result:      Tuple(NewPoint(-8, 7, 3)),
 			transform:   Translation(5, -3, 2).Inverse(),
 			description: "multiplying by the inverse of a translation matrix",
 		},
 		TransformTestCase{
-			original:    NewVector(-3, 4, 5),
-			result:      NewVector(-3, 4, 5),
+			original:    Tuple(NewVector(-3, 4, 5)),
+			result:      Tuple(NewVector(-3, 4, 5)),
 			transform:   Translation(5, -3, 2),
 			description: "translation does not affect vectors",
 		},
 		TransformTestCase{
 			transform:   Scaling(2, 3, 4),
-			original:    NewPoint(-4, 6, 8),
-			result:      NewPoint(-8, 18, 32),
+			original:    Tuple(NewPoint(-4, 6, 8)),
+			result:      Tuple(NewPoint(-8, 18, 32)),
 			description: "scaling matrix applied to a point",
 		},
 		TransformTestCase{
 			transform:   Scaling(2, 3, 4),
-			original:    NewVector(-4, 6, 8),
-			result:      NewVector(-8, 18, 32),
+			original:    Tuple(NewVector(-4, 6, 8)),
+			result:      Tuple(NewVector(-8, 18, 32)),
 			description: "scaling matrix applied to a vector",
 		},
 		TransformTestCase{
 			transform:   Scaling(2, 3, 4).Inverse(),
-			original:    NewVector(-4, 6, 8),
-			result:      NewVector(-2, 2, 2),
+			original:    Tuple(NewVector(-4, 6, 8)),
+			result:      Tuple(NewVector(-2, 2, 2)),
 			description: "multiplying by the inverse of a scaling matrix",
 		},
 		TransformTestCase{
 			transform:   Scaling(-1, 1, 1),
-			original:    NewVector(2, 3, 4),
-			result:      NewVector(-2, 3, 4),
+			original:    Tuple(NewVector(2, 3, 4)),
+			result:      Tuple(NewVector(-2, 3, 4)),
 			description: "reflection is scaling by a negative value",
 		},
 		TransformTestCase{
 			transform:   RotationX(math.Pi / 4),
-			original:    NewVector(0, 1, 0),
-			result:      NewVector(0, math.Sqrt2/2, math.Sqrt2/2),
+			original:    Tuple(NewVector(0, 1, 0)),
+			result:      Tuple(NewVector(0, math.Sqrt2/2, math.Sqrt2/2)),
 			description: "rotating a point π/4 around the x axis",
 		},
 		TransformTestCase{
 			transform:   RotationX(math.Pi / 2),
-			original:    NewVector(0, 1, 0),
-			result:      NewVector(0, 0, 1),
+			original:    Tuple(NewVector(0, 1, 0)),
+			result:      Tuple(NewVector(0, 0, 1)),
 			description: "rotating a point π/2 around the x axis",
 		},
 		TransformTestCase{
 			transform:   RotationX(math.Pi / 4).Inverse(),
-			original:    NewVector(0, 1, 0),
-			result:      NewVector(0, math.Sqrt2/2, -math.Sqrt2/2),
+			original:    Tuple(NewVector(0, 1, 0)),
+			result:      Tuple(NewVector(0, math.Sqrt2/2, -math.Sqrt2/2)),
 			description: "the inverse of an x-rotation rotates in the opposite direction",
 		},
 		TransformTestCase{
 			transform:   RotationY(math.Pi / 4),
-			original:    NewVector(0, 0, 1),
-			result:      NewVector(math.Sqrt2/2, 0, math.Sqrt2/2),
+			original:    Tuple(NewVector(0, 0, 1)),
+			result:      Tuple(NewVector(math.Sqrt2/2, 0, math.Sqrt2/2)),
 			description: "rotating a point π/4 around the y axis",
 		},
 		TransformTestCase{
 			transform:   RotationY(math.Pi / 2),
-			original:    NewVector(0, 0, 1),
-			result:      NewVector(1, 0, 0),
+			original:    Tuple(NewVector(0, 0, 1)),
+			result:      Tuple(NewVector(1, 0, 0)),
 			description: "rotating a point π/2 around the y axis",
 		},
 		TransformTestCase{
 			transform:   RotationZ(math.Pi / 4),
-			original:    NewVector(0, 1, 0),
-			result:      NewVector(-math.Sqrt2/2, math.Sqrt2/2, 0),
+			original:    Tuple(NewVector(0, 1, 0)),
+			result:      Tuple(NewVector(-math.Sqrt2/2, math.Sqrt2/2, 0)),
 			description: "rotating a point π/4 around the z axis",
 		},
 		TransformTestCase{
 			transform:   RotationZ(math.Pi / 2),
-			original:    NewVector(0, 1, 0),
-			result:      NewVector(-1, 0, 0),
+			original:    Tuple(NewVector(0, 1, 0)),
+			result:      Tuple(NewVector(-1, 0, 0)),
 			description: "rotating a point π/2 around the z axis",
 		},
 		TransformTestCase{
 			transform:   Shearing(1, 0, 0, 0, 0, 0),
-			original:    NewVector(2, 3, 4),
-			result:      NewVector(5, 3, 4),
+			original:    Tuple(NewVector(2, 3, 4)),
+			result:      Tuple(NewVector(5, 3, 4)),
 			description: "shearing moves x in proportion to y",
 		},
 		TransformTestCase{
 			transform:   Shearing(0, 1, 0, 0, 0, 0),
-			original:    NewVector(2, 3, 4),
-			result:      NewVector(6, 3, 4),
+			original:    Tuple(NewVector(2, 3, 4)),
+			result:      Tuple(NewVector(6, 3, 4)),
 			description: "shearing moves x in proportion to z",
 		},
 		TransformTestCase{
 			transform:   Shearing(0, 0, 1, 0, 0, 0),
-			original:    NewVector(2, 3, 4),
-			result:      NewVector(2, 5, 4),
+			original:    Tuple(NewVector(2, 3, 4)),
+			result:      Tuple(NewVector(2, 5, 4)),
 			description: "shearing moves y in proportion to x",
 		},
 		TransformTestCase{
 			transform:   Shearing(0, 0, 0, 1, 0, 0),
-			original:    NewVector(2, 3, 4),
-			result:      NewVector(2, 7, 4),
+			original:    Tuple(NewVector(2, 3, 4)),
+			result:      Tuple(NewVector(2, 7, 4)),
 			description: "shearing moves y in proportion to z",
 		},
 		TransformTestCase{
 			transform:   Shearing(0, 0, 0, 0, 1, 0),
-			original:    NewVector(2, 3, 4),
-			result:      NewVector(2, 3, 6),
+			original:    Tuple(NewVector(2, 3, 4)),
+			result:      Tuple(NewVector(2, 3, 6)),
 			description: "shearing moves z in proportion to x",
 		},
 		TransformTestCase{
 			transform:   Shearing(0, 0, 0, 0, 0, 1),
-			original:    NewVector(2, 3, 4),
-			result:      NewVector(2, 3, 7),
+			original:    Tuple(NewVector(2, 3, 4)),
+			result:      Tuple(NewVector(2, 3, 7)),
 			description: "shearing moves z in proportion to y",
 		},
 	}
@@ -153,13 +153,13 @@ func TestChainingTransformations(t *testing.T) {
 		b := Scaling(5, 5, 5)
 		c := Translation(10, 5, 7)
 
-		p2 := a.Mult(p)
+		p2 := a.MulPoint(p)
 		assert.True(t, TuplesEqual(p2, NewPoint(1, -1, 0)))
 
-		p3 := b.Mult(p2)
+		p3 := b.MulPoint(p2)
 		assert.True(t, TuplesEqual(p3, NewPoint(5, -5, 0)))
 
-		p4 := c.Mult(p3)
+		p4 := c.MulPoint(p3)
 		assert.True(t, TuplesEqual(p4, NewPoint(15, 0, 7)))
 	})
 
@@ -171,6 +171,40 @@ func TestChainingTransformations(t *testing.T) {
 
 		transform := c.Mul(b).Mul(a)
 
-		assert.True(t, TuplesEqual(transform.Mult(p), NewPoint(15, 0, 7)))
+		assert.True(t, TuplesEqual(transform.MulPoint(p), NewPoint(15, 0, 7)))
+	})
+}
+
+func TestTransformBuilder(t *testing.T) {
+	t.Run("applies transformations in the order they are chained", func(t *testing.T) {
+		transform := NewTransformBuilder().RotateX(math.Pi/2).Scale(5, 5, 5).Translate(10, 5, 7).Matrix()
+
+		assert.True(t, MatricesEqual(transform, Translation(10, 5, 7).Mul(Scaling(5, 5, 5)).Mul(RotationX(math.Pi/2))))
+		assert.True(t, TuplesEqual(transform.MulPoint(NewPoint(1, 0, 1)), NewPoint(15, 0, 7)))
+	})
+
+	t.Run("starts from the identity", func(t *testing.T) {
+		assert.Equal(t, NewTransformBuilder().Matrix(), IdentityMatrix())
+	})
+
+	t.Run("does not change a shared chain", func(t *testing.T) {
+		base := NewTransformBuilder().Scale(2, 2, 2)
+		moved := base.Translate(1, 0, 0)
+
+		assert.True(t, MatricesEqual(base.Matrix(), Scaling(2, 2, 2)))
+		assert.True(t, MatricesEqual(moved.Matrix(), Translation(1, 0, 0).Mul(Scaling(2, 2, 2))))
+	})
+
+	t.Run("rotates about an arbitrary axis", func(t *testing.T) {
+		transform := NewTransformBuilder().RotateAbout(NewVector(1, 1, 0), math.Pi).Matrix()
+
+		assert.True(t, TuplesEqual(transform.MulVector(NewVector(1, 0, 0)), NewVector(0, 1, 0)))
+		assert.True(t, TuplesEqual(transform.MulVector(NewVector(0, 0, 1)), NewVector(0, 0, -1)))
+	})
+
+	t.Run("shears", func(t *testing.T) {
+		transform := NewTransformBuilder().Shear(1, 0, 0, 0, 0, 0).Then(Translation(0, 1, 0)).Matrix()
+
+		assert.True(t, TuplesEqual(transform.MulPoint(NewPoint(2, 3, 4)), NewPoint(5, 4, 4)))
 	})
 }
@@ -0,0 +1,139 @@
+package goray
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+type Fog struct {
+	Color   Color
+	Density float64
+}
+
+func NewFog(color Color, density float64) Fog {
+	return Fog{Color: color, Density: density}
+}
+
+// Apply fades c towards the fog's colour over distance. Rays that escape the
+// scene are lost in the fog entirely, unless there is no fog to lose them in.
+func (f Fog) Apply(c Color, distance float64) Color {
+	if f.Density <= 0 {
+		return c
+	}
+	if math.IsInf(distance, 1) {
+		return f.Color
+	}
+	transmittance := math.Exp(-f.Density * distance)
+	return c.Mul(transmittance).Add(f.Color.Mul(1.0 - transmittance))
+}
+
+// Volume is a homogeneous participating medium filling the inside of
+// Boundary. Light passing through it is absorbed and out-scattered according
+// to Absorption and Scattering, and light from the world's point light is
+// scattered towards the viewer, estimated by marching Steps points along each
+// ray segment inside the volume. The boundary shape should not also be one of
+// the world's Objects.
+type Volume struct {
+	Boundary               Shape
+	Absorption, Scattering float64
+	Color                  Color
+	Steps                  int
+}
+
+func NewVolume(boundary Shape, absorption, scattering float64) Volume {
+	return Volume{
+		Boundary:   boundary,
+		Absorption: absorption,
+		Scattering: scattering,
+		Color:      White(),
+		Steps:      16,
+	}
+}
+
+func (v Volume) Extinction() float64 {
+	return v.Absorption + v.Scattering
+}
+
+// Segments returns the [t0, t1] spans of r, clipped to [0, tMax], that lie
+// inside the volume's boundary.
+func (v Volume) Segments(r Ray, tMax float64) [][2]float64 {
+	xs := r.Intersect(v.Boundary)
+	slices.SortFunc(xs, func(a, b Intersection) int {
+		return cmp.Compare(a.T, b.T)
+	})
+
+	segments := [][2]float64{}
+	inside := false
+	start := 0.0
+	for _, x := range xs {
+		if inside {
+			t0, t1 := math.Max(start, 0.0), math.Min(x.T, tMax)
+			if t1 > t0 {
+				segments = append(segments, [2]float64{t0, t1})
+			}
+		} else {
+			start = x.T
+		}
+		inside = !inside
+	}
+	return segments
+}
+
+func (v Volume) transmittance(r Ray, tMax float64) float64 {
+	length := 0.0
+	for _, segment := range v.Segments(r, tMax) {
+		length += segment[1] - segment[0]
+	}
+	return math.Exp(-v.Extinction() * length * r.Direction.Magnitude())
+}
+
+type volumeSegment struct {
+	volume Volume
+	t0, t1 float64
+}
+
+func (w World) applyMedia(r Ray, tHit float64, c Color) Color {
+	segments := []volumeSegment{}
+	for _, v := range w.Volumes {
+		for _, s := range v.Segments(r, tHit) {
+			segments = append(segments, volumeSegment{volume: v, t0: s[0], t1: s[1]})
+		}
+	}
+	slices.SortFunc(segments, func(a, b volumeSegment) int {
+		return cmp.Compare(b.t0, a.t0)
+	})
+
+	for _, s := range segments {
+		c = w.marchVolume(r, s, c)
+	}
+
+	if w.Fog != nil {
+		c = w.Fog.Apply(c, tHit*r.Direction.Magnitude())
+	}
+	return c
+}
+
+func (w World) marchVolume(r Ray, s volumeSegment, behind Color) Color {
+	speed := r.Direction.Magnitude()
+	extinction := s.volume.Extinction()
+	steps := max(s.volume.Steps, 1)
+	dt := (s.t1 - s.t0) / float64(steps)
+	phase := 1.0 / (4.0 * math.Pi)
+
+	scattered := Black()
+	for i := range steps {
+		t := s.t0 + (float64(i)+0.5)*dt
+		point := r.At(t)
+		viewTransmittance := math.Exp(-extinction * (t - s.t0) * speed)
+		lightTransmittance := w.ShadowTransmittance(point, w.LightSource.Position)
+		if lightTransmittance == 0.0 {
+			continue
+		}
+		inscatter := w.LightSource.Intensity.Mul(lightTransmittance * phase * s.volume.Scattering * viewTransmittance * dt * speed)
+		scattered = scattered.Add(inscatter)
+	}
+
+	transmittance := math.Exp(-extinction * (s.t1 - s.t0) * speed)
+	return behind.Mul(transmittance).Add(scattered.Prod(s.volume.Color))
+}
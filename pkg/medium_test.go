@@ -0,0 +1,124 @@
+package goray
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFog(t *testing.T) {
+	f := NewFog(NewColor(0.5, 0.5, 0.5), 0.1)
+
+	t.Run("at no distance", func(t *testing.T) {
+		assert.True(t, TuplesEqual(f.Apply(White(), 0), White()))
+	})
+
+	t.Run("at some distance", func(t *testing.T) {
+		e := math.Exp(-0.5)
+		expected := NewColor(e+0.5*(1-e), e+0.5*(1-e), e+0.5*(1-e))
+		assert.True(t, TuplesEqual(f.Apply(White(), 5), expected))
+	})
+
+	t.Run("at infinite distance", func(t *testing.T) {
+		assert.Equal(t, f.Apply(White(), math.Inf(1)), f.Color)
+	})
+
+	t.Run("with no density", func(t *testing.T) {
+		clear := NewFog(NewColor(0.5, 0.5, 0.5), 0)
+		assert.Equal(t, clear.Apply(White(), 5), White())
+		assert.Equal(t, clear.Apply(White(), math.Inf(1)), White())
+	})
+}
+
+func TestVolumeSegments(t *testing.T) {
+	s := NewSphere()
+	v := NewVolume(&s, 0.5, 0.5)
+
+	t.Run("for a ray passing through", func(t *testing.T) {
+		r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+		assert.Equal(t, v.Segments(r, math.Inf(1)), [][2]float64{{4, 6}})
+	})
+
+	t.Run("for a ray starting inside", func(t *testing.T) {
+		r := NewRay(NewPoint(0, 0, 0), NewVector(0, 0, 1))
+		assert.Equal(t, v.Segments(r, math.Inf(1)), [][2]float64{{0, 1}})
+	})
+
+	t.Run("clipped by a surface", func(t *testing.T) {
+		r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+		assert.Equal(t, v.Segments(r, 5), [][2]float64{{4, 5}})
+	})
+
+	t.Run("for a ray that misses", func(t *testing.T) {
+		r := NewRay(NewPoint(0, 2, -5), NewVector(0, 0, 1))
+		assert.Empty(t, v.Segments(r, math.Inf(1)))
+	})
+}
+
+func TestColorAtWithMedia(t *testing.T) {
+	t.Run("fog fills rays that miss", func(t *testing.T) {
+		w := defaultWorld()
+		w.Fog = &Fog{Color: NewColor(0.7, 0.7, 0.7), Density: 0.2}
+
+		c := w.ColorAt(NewRay(NewPoint(0, 0, -5), NewVector(0, 1, 0)), 0)
+
+		assert.True(t, TuplesEqual(c, NewColor(0.7, 0.7, 0.7)))
+	})
+
+	t.Run("fog fades distant hits", func(t *testing.T) {
+		w := defaultWorld()
+		w.Fog = &Fog{Color: Black(), Density: 0.2}
+
+		c := w.ColorAt(NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1)), 0)
+
+		assert.True(t, TuplesEqual(c, NewColor(0.38066, 0.47583, 0.2855).Mul(math.Exp(-0.8))))
+	})
+
+	t.Run("an absorbing volume darkens what is behind it", func(t *testing.T) {
+		w := NewWorld()
+		w.Background = NewConstantBackground(White())
+		s := NewSphere()
+		w.Volumes = []Volume{NewVolume(&s, 0.5, 0)}
+
+		c := w.ColorAt(NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1)), 0)
+
+		e := math.Exp(-1)
+		assert.True(t, TuplesEqual(c, NewColor(e, e, e)))
+	})
+
+	t.Run("a scattering volume glows where it is lit", func(t *testing.T) {
+		w := NewWorld()
+		w.LightSource = NewPointLight(NewPoint(0, 10, 0), White())
+		s := NewSphere()
+		w.Volumes = []Volume{NewVolume(&s, 0, 0.5)}
+
+		c := w.ColorAt(NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1)), 0)
+
+		assert.Greater(t, c.x, 0.0)
+	})
+
+	t.Run("a scattering volume stays dark in shadow", func(t *testing.T) {
+		w := NewWorld()
+		w.LightSource = NewPointLight(NewPoint(0, 10, 0), White())
+		blocker := NewPlane()
+		blocker.SetTransform(Translation(0, 5, 0))
+		w.Objects = []Shape{&blocker}
+		s := NewSphere()
+		w.Volumes = []Volume{NewVolume(&s, 0, 0.5)}
+
+		c := w.ColorAt(NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1)), 0)
+
+		assert.True(t, TuplesEqual(c, Black()))
+	})
+}
+
+func TestShadowTransmittanceThroughVolume(t *testing.T) {
+	w := NewWorld()
+	s := NewSphere()
+	w.Volumes = []Volume{NewVolume(&s, 0.25, 0.25)}
+
+	transmittance := w.ShadowTransmittance(NewPoint(0, 0, 5), NewPoint(0, 0, -5))
+
+	assert.InDelta(t, transmittance, math.Exp(-1), 0.00001)
+}
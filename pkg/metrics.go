@@ -0,0 +1,133 @@
+package goray
+
+import "math"
+
+// The metrics below compare two canvases of the same size and treat 1.0 as
+// the brightest value a channel can hold, so they are meant for images that
+// have been tone mapped or clamped for display.
+
+func (c Canvas) sameSize(d Canvas) bool {
+	return c.Width == d.Width && c.Height == d.Height
+}
+
+func meanSquared(a, b Canvas) float64 {
+	if !a.sameSize(b) {
+		panic("canvases need the same size to be compared")
+	}
+	sum := 0.0
+	for i, p := range a.Pixels {
+		d := p.Sub(b.Pixels[i])
+		sum += d.x*d.x + d.y*d.y + d.z*d.z
+	}
+	return sum / float64(3*len(a.Pixels))
+}
+
+// RMSE is the root mean squared difference over every channel of every pixel.
+func RMSE(a, b Canvas) float64 {
+	return math.Sqrt(meanSquared(a, b))
+}
+
+// PSNR is the peak signal to noise ratio in decibels. Identical canvases give
+// positive infinity.
+func PSNR(a, b Canvas) float64 {
+	mse := meanSquared(a, b)
+	if mse == 0.0 {
+		return math.Inf(1)
+	}
+	return 10.0 * math.Log10(1.0/mse)
+}
+
+// SSIM is the mean structural similarity of the canvases' luminance, using
+// the Gaussian weighted 11×11 windows and constants of Wang et al. It is 1 for
+// identical images and falls towards 0 as their structure diverges.
+func SSIM(a, b Canvas) float64 {
+	if !a.sameSize(b) {
+		panic("canvases need the same size to be compared")
+	}
+	const sigma = 1.5
+	const c1 = 0.01 * 0.01
+	const c2 = 0.03 * 0.03
+
+	la, lb := a.luminance(), b.luminance()
+	muA, muB := la.GaussianBlur(sigma), lb.GaussianBlur(sigma)
+	aa := la.mulChannels(la).GaussianBlur(sigma)
+	bb := lb.mulChannels(lb).GaussianBlur(sigma)
+	ab := la.mulChannels(lb).GaussianBlur(sigma)
+
+	sum := 0.0
+	for i := range la.Pixels {
+		ma, mb := muA.Pixels[i].x, muB.Pixels[i].x
+		varA := aa.Pixels[i].x - ma*ma
+		varB := bb.Pixels[i].x - mb*mb
+		covariance := ab.Pixels[i].x - ma*mb
+		sum += ((2.0*ma*mb + c1) * (2.0*covariance + c2)) /
+			((ma*ma + mb*mb + c1) * (varA + varB + c2))
+	}
+	return sum / float64(len(la.Pixels))
+}
+
+func (c Canvas) luminance() Canvas {
+	out := blankCanvas(c.Width, c.Height)
+	for i, p := range c.Pixels {
+		l := 0.2126*p.x + 0.7152*p.y + 0.0722*p.z
+		out.Pixels[i] = NewColor(l, l, l)
+	}
+	return out
+}
+
+func (c Canvas) mulChannels(d Canvas) Canvas {
+	out := blankCanvas(c.Width, c.Height)
+	for i, p := range c.Pixels {
+		out.Pixels[i] = p.Prod(d.Pixels[i])
+	}
+	return out
+}
+
+// DifferingPixels counts the pixels where any channel of a and b differs by
+// more than tolerance.
+func DifferingPixels(a, b Canvas, tolerance float64) int {
+	if !a.sameSize(b) {
+		panic("canvases need the same size to be compared")
+	}
+	count := 0
+	for i, p := range a.Pixels {
+		if maxChannelDifference(p, b.Pixels[i]) > tolerance {
+			count++
+		}
+	}
+	return count
+}
+
+// DiffHeatmap shows where two canvases differ. Each pixel's largest channel
+// difference is scaled against the largest difference in the image and drawn
+// from black through red and yellow to white.
+func DiffHeatmap(a, b Canvas) Canvas {
+	if !a.sameSize(b) {
+		panic("canvases need the same size to be compared")
+	}
+	diffs := make([]float64, len(a.Pixels))
+	worst := 0.0
+	for i, p := range a.Pixels {
+		diffs[i] = maxChannelDifference(p, b.Pixels[i])
+		worst = math.Max(worst, diffs[i])
+	}
+
+	out := blankCanvas(a.Width, a.Height)
+	if worst == 0.0 {
+		return out
+	}
+	for i, d := range diffs {
+		t := 3.0 * d / worst
+		out.Pixels[i] = NewColor(
+			math.Min(t, 1.0),
+			math.Max(0.0, math.Min(t-1.0, 1.0)),
+			math.Max(0.0, math.Min(t-2.0, 1.0)),
+		)
+	}
+	return out
+}
+
+func maxChannelDifference(a, b Color) float64 {
+	d := a.Sub(b)
+	return math.Max(math.Abs(d.x), math.Max(math.Abs(d.y), math.Abs(d.z)))
+}
@@ -0,0 +1,83 @@
+package goray
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func filledCanvas(width, height int, c Color) Canvas {
+	canvas := blankCanvas(width, height)
+	for i := range canvas.Pixels {
+		canvas.Pixels[i] = c
+	}
+	return canvas
+}
+
+func TestRMSEAndPSNR(t *testing.T) {
+	a := filledCanvas(4, 4, NewColor(0.5, 0.5, 0.5))
+
+	assert.Equal(t, RMSE(a, a), 0.0)
+	assert.True(t, math.IsInf(PSNR(a, a), 1))
+
+	b := filledCanvas(4, 4, NewColor(0.6, 0.5, 0.5))
+	assert.InDelta(t, RMSE(a, b), 0.1/math.Sqrt(3), 1e-9)
+	assert.InDelta(t, PSNR(a, b), 10*math.Log10(300), 1e-9)
+
+	assert.Panics(t, func() { RMSE(a, blankCanvas(2, 2)) })
+}
+
+func TestSSIM(t *testing.T) {
+	a := blankCanvas(16, 16)
+	for y := range 16 {
+		for x := range 16 {
+			if (x/4+y/4)%2 == 0 {
+				a.Write(x, y, White())
+			}
+		}
+	}
+
+	assert.InDelta(t, SSIM(a, a), 1.0, 1e-9)
+
+	dimmer := a.PostProcess(PostProcess{Exposure: -0.1})
+	blurred := a.GaussianBlur(1.5)
+	assert.Less(t, SSIM(a, blurred), SSIM(a, dimmer))
+	assert.Less(t, SSIM(a, filledCanvas(16, 16, NewColor(0.5, 0.5, 0.5))), 0.1)
+}
+
+func TestDifferingPixels(t *testing.T) {
+	a := blankCanvas(3, 1)
+	b := blankCanvas(3, 1)
+	b.Write(0, 0, NewColor(0, 0.05, 0))
+	b.Write(1, 0, NewColor(0, 0, -0.2))
+
+	assert.Equal(t, DifferingPixels(a, b, 0.1), 1)
+	assert.Equal(t, DifferingPixels(a, b, 0.01), 2)
+}
+
+func TestDiffHeatmap(t *testing.T) {
+	a := blankCanvas(3, 1)
+	b := blankCanvas(3, 1)
+	b.Write(1, 0, NewColor(0.1, 0, 0))
+	b.Write(2, 0, NewColor(0, 0.2, 0))
+
+	heatmap := DiffHeatmap(a, b)
+	assert.Equal(t, heatmap.At(0, 0), Black())
+	assert.True(t, TuplesEqual(heatmap.At(1, 0), NewColor(1, 0.5, 0)))
+	assert.Equal(t, heatmap.At(2, 0), White())
+
+	assert.Equal(t, DiffHeatmap(a, a).Pixels, a.Pixels)
+}
+
+func TestCanvasFromImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(2, 3, 4, 4))
+	img.Set(3, 3, color.RGBA{R: 255, G: 51, B: 0, A: 255})
+
+	c := CanvasFromImage(img)
+	assert.Equal(t, c.Width, 2)
+	assert.Equal(t, c.Height, 1)
+	assert.True(t, TuplesEqual(c.At(1, 0), NewColor(1, 0.2, 0)))
+}
@@ -0,0 +1,53 @@
+package goray
+
+import "math"
+
+// microfacetLighting evaluates a GGX / Smith / Fresnel-Schlick BRDF for a
+// single point light. The result is scaled by π so that a white Lambertian
+// surface is as bright as the Phong diffuse term for the same light.
+func (m Material) microfacetLighting(s Shape, light PointLight, point Point, eyev, normalv Vector) Color {
+	baseColor := PatternAtObject(m.Pattern, s, point)
+
+	lightv := light.Position.Sub(point).Normalize()
+	nDotL := normalv.Dot(lightv)
+	if nDotL <= 0.0 {
+		return Black()
+	}
+	nDotV := math.Max(normalv.Dot(eyev), 0.0001)
+
+	halfv := lightv.Add(eyev).Normalize()
+	nDotH := math.Max(normalv.Dot(halfv), 0.0)
+	vDotH := math.Max(eyev.Dot(halfv), 0.0)
+
+	f0 := math.Pow((m.RefractiveIndex-1.0)/(m.RefractiveIndex+1.0), 2)
+	specularColor := NewColor(f0, f0, f0).Mul(1.0 - m.Metallic).Add(baseColor.Mul(m.Metallic))
+	fresnel := fresnelSchlick(specularColor, vDotH)
+
+	d := ggxDistribution(nDotH, m.Roughness)
+	g := smithGeometry(nDotL, nDotV, m.Roughness)
+	specular := fresnel.Mul(d * g / (4.0 * nDotL * nDotV))
+
+	kd := White().Sub(fresnel).Mul(1.0 - m.Metallic)
+	diffuse := kd.Prod(baseColor).Div(math.Pi)
+
+	return diffuse.Add(specular).Prod(light.Intensity).Mul(nDotL * math.Pi)
+}
+
+func ggxDistribution(nDotH, roughness float64) float64 {
+	alpha := math.Max(roughness*roughness, 0.001)
+	alpha2 := alpha * alpha
+	denom := nDotH*nDotH*(alpha2-1.0) + 1.0
+	return alpha2 / (math.Pi * denom * denom)
+}
+
+func smithGeometry(nDotL, nDotV, roughness float64) float64 {
+	k := (roughness + 1.0) * (roughness + 1.0) / 8.0
+	g1 := func(x float64) float64 {
+		return x / (x*(1.0-k) + k)
+	}
+	return g1(nDotL) * g1(nDotV)
+}
+
+func fresnelSchlick(f0 Color, cos float64) Color {
+	return f0.Add(White().Sub(f0).Mul(math.Pow(1.0-cos, 5)))
+}
@@ -0,0 +1,60 @@
+package goray
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMicrofacetLighting(t *testing.T) {
+	s := NewSphere()
+	position := NewPoint(0, 0, 0)
+	eyev := NewVector(0, 0, -1)
+	normalv := NewVector(0, 0, -1)
+	light := NewPointLight(NewPoint(0, 0, -10), White())
+
+	t.Run("for a rough dielectric", func(t *testing.T) {
+		m := NewPBRMaterial(White(), 0.0, 0.5, 1.5)
+		c := m.Lighting(&s, light, position, eyev, normalv, 1.0)
+		assert.True(t, TuplesEqual(c, NewColor(1.22, 1.22, 1.22)))
+	})
+
+	t.Run("for a rough metal", func(t *testing.T) {
+		m := NewPBRMaterial(NewColor(1, 0.5, 0.25), 1.0, 0.5, 1.5)
+		c := m.Lighting(&s, light, position, eyev, normalv, 1.0)
+		assert.True(t, TuplesEqual(c, NewColor(4.1, 2.05, 1.025)))
+	})
+
+	t.Run("with the light behind the surface", func(t *testing.T) {
+		m := NewPBRMaterial(White(), 0.0, 0.5, 1.5)
+		behind := NewPointLight(NewPoint(0, 0, 10), White())
+		c := m.Lighting(&s, behind, position, eyev, normalv, 1.0)
+		assert.True(t, TuplesEqual(c, NewColor(0.1, 0.1, 0.1)))
+	})
+
+	t.Run("with the surface in shadow", func(t *testing.T) {
+		m := NewPBRMaterial(White(), 0.0, 0.5, 1.5)
+		c := m.Lighting(&s, light, position, eyev, normalv, 0.0)
+		assert.True(t, TuplesEqual(c, NewColor(0.1, 0.1, 0.1)))
+	})
+
+	t.Run("is brighter at the highlight for smoother surfaces", func(t *testing.T) {
+		rough := NewPBRMaterial(White(), 0.0, 0.8, 1.5)
+		smooth := NewPBRMaterial(White(), 0.0, 0.2, 1.5)
+		c1 := rough.Lighting(&s, light, position, eyev, normalv, 1.0)
+		c2 := smooth.Lighting(&s, light, position, eyev, normalv, 1.0)
+		assert.Greater(t, c2.x, c1.x)
+	})
+}
+
+func TestGGXDistribution(t *testing.T) {
+	assert.InDelta(t, ggxDistribution(1.0, 0.5), 1.0/(math.Pi*0.0625), 0.00001)
+}
+
+func TestFresnelSchlick(t *testing.T) {
+	f0 := NewColor(0.04, 0.04, 0.04)
+
+	assert.True(t, TuplesEqual(fresnelSchlick(f0, 1.0), f0))
+	assert.True(t, TuplesEqual(fresnelSchlick(f0, 0.0), White()))
+}
@@ -37,8 +37,8 @@ func (sp *SolidPattern) At(_ Point) Color {
 }
 
 func (p *SolidPattern) AtObject(shape Shape, point Point) Color {
-	objectPoint := shape.GetTransform().Inverse().Mult(point)
-	patternPoint := p.GetTransform().Inverse().Mult(objectPoint)
+	objectPoint := shape.GetTransform().Inverse().MulPoint(point)
+	patternPoint := p.GetTransform().Inverse().MulPoint(objectPoint)
 	return p.At(patternPoint)
 }
 
@@ -67,8 +67,8 @@ func (sp *StripePattern) At(p Point) Color {
 }
 
 func (p *StripePattern) AtObject(shape Shape, point Point) Color {
-	objectPoint := shape.GetTransform().Inverse().Mult(point)
-	patternPoint := p.GetTransform().Inverse().Mult(objectPoint)
+	objectPoint := shape.GetTransform().Inverse().MulPoint(point)
+	patternPoint := p.GetTransform().Inverse().MulPoint(objectPoint)
 	return p.At(patternPoint)
 }
 
@@ -94,8 +94,8 @@ func (gp *GradientPattern) At(p Point) Color {
 }
 
 func (p *GradientPattern) AtObject(shape Shape, point Point) Color {
-	objectPoint := shape.GetTransform().Inverse().Mult(point)
-	patternPoint := p.GetTransform().Inverse().Mult(objectPoint)
+	objectPoint := shape.GetTransform().Inverse().MulPoint(point)
+	patternPoint := p.GetTransform().Inverse().MulPoint(objectPoint)
 	return p.At(patternPoint)
 }
 
@@ -124,8 +124,8 @@ func (rp *RingPattern) At(p Point) Color {
 }
 
 func (p *RingPattern) AtObject(shape Shape, point Point) Color {
-	objectPoint := shape.GetTransform().Inverse().Mult(point)
-	patternPoint := p.GetTransform().Inverse().Mult(objectPoint)
+	objectPoint := shape.GetTransform().Inverse().MulPoint(point)
+	patternPoint := p.GetTransform().Inverse().MulPoint(objectPoint)
 	return p.At(patternPoint)
 }
 
@@ -154,8 +154,8 @@ func (cp *CheckersPattern) At(p Point) Color {
 }
 
 func (p *CheckersPattern) AtObject(shape Shape, point Point) Color {
-	objectPoint := shape.GetTransform().Inverse().Mult(point)
-	patternPoint := p.GetTransform().Inverse().Mult(objectPoint)
+	objectPoint := shape.GetTransform().Inverse().MulPoint(point)
+	patternPoint := p.GetTransform().Inverse().MulPoint(objectPoint)
 	return p.At(patternPoint)
 }
 
@@ -177,6 +177,6 @@ func (bp *BlendedPattern) At(p Point) Color {
 }
 
 func (p *BlendedPattern) AtObject(shape Shape, point Point) Color {
-	patternPoint := p.GetTransform().Inverse().Mult(point)
+	patternPoint := p.GetTransform().Inverse().MulPoint(point)
 	return p.A.AtObject(shape, patternPoint).Add(p.B.AtObject(shape, patternPoint))
 }
@@ -0,0 +1,67 @@
+package goray
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WritePFM writes the canvas as a little-endian colour Portable Float Map,
+// which keeps the full range of the linear values.
+func (c Canvas) WritePFM(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "PF\n%d %d\n-1.0\n", c.Width, c.Height)
+
+	row := make([]byte, c.Width*12)
+	for y := c.Height - 1; y >= 0; y-- {
+		for x := range c.Width {
+			p := c.At(x, y)
+			binary.LittleEndian.PutUint32(row[x*12:], math.Float32bits(float32(p.x)))
+			binary.LittleEndian.PutUint32(row[x*12+4:], math.Float32bits(float32(p.y)))
+			binary.LittleEndian.PutUint32(row[x*12+8:], math.Float32bits(float32(p.z)))
+		}
+		if _, err := bw.Write(row); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func LoadPFM(r io.Reader) (Canvas, error) {
+	br := bufio.NewReader(r)
+
+	var magic string
+	var width, height int
+	var scale float64
+	if _, err := fmt.Fscanf(br, "%s\n%d %d\n%f\n", &magic, &width, &height, &scale); err != nil {
+		return Canvas{}, fmt.Errorf("pfm: %w", err)
+	}
+	if magic != "PF" {
+		return Canvas{}, fmt.Errorf("pfm: unsupported type %q", magic)
+	}
+	if err := checkImageSize(width, height); err != nil {
+		return Canvas{}, fmt.Errorf("pfm: %w", err)
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if scale > 0 {
+		order = binary.BigEndian
+	}
+
+	canvas := blankCanvas(width, height)
+	row := make([]byte, width*12)
+	for y := height - 1; y >= 0; y-- {
+		if _, err := io.ReadFull(br, row); err != nil {
+			return Canvas{}, fmt.Errorf("pfm: %w", err)
+		}
+		for x := range width {
+			canvas.Write(x, y, NewColor(
+				float64(math.Float32frombits(order.Uint32(row[x*12:]))),
+				float64(math.Float32frombits(order.Uint32(row[x*12+4:]))),
+				float64(math.Float32frombits(order.Uint32(row[x*12+8:]))),
+			))
+		}
+	}
+	return canvas, nil
+}
@@ -0,0 +1,38 @@
+package goray
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPFMRoundTrip(t *testing.T) {
+	c := NewCanvas(3, 1.5)
+	c.Write(0, 0, NewColor(1.5, -2, 0.25))
+	c.Write(2, 1, NewColor(100, 0.001, 3))
+
+	var buf bytes.Buffer
+	assert.NoError(t, c.WritePFM(&buf))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("PF\n3 2\n-1.0\n")))
+
+	d, err := LoadPFM(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, d.Width, 3)
+	assert.Equal(t, d.Height, 2)
+	for i := range c.Pixels {
+		assert.True(t, TuplesEqual(c.Pixels[i], d.Pixels[i]))
+	}
+}
+
+func TestLoadPFMRejectsGreyscale(t *testing.T) {
+	_, err := LoadPFM(bytes.NewBufferString("Pf\n1 1\n-1.0\n\x00\x00\x00\x00"))
+	assert.Error(t, err)
+}
+
+func TestLoadPFMRejectsBadSizes(t *testing.T) {
+	for _, size := range []string{"0 1", "1 -1", "-4 -4", "100000 1", "65536 65536"} {
+		_, err := LoadPFM(bytes.NewBufferString("PF\n" + size + "\n-1.0\n"))
+		assert.ErrorContains(t, err, "image size", size)
+	}
+}
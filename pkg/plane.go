@@ -3,9 +3,10 @@ package goray
 import "math"
 
 type Plane struct {
-	Material  Material
-	Transform Matrix
-	SavedRay  Ray
+	Material     Material
+	Transform    Matrix
+	EndTransform *Matrix
+	SavedRay     Ray
 }
 
 func NewPlane() Plane {
@@ -31,6 +32,14 @@ func (p *Plane) SetTransform(m Matrix) {
 	p.Transform = m
 }
 
+func (p *Plane) GetEndTransform() *Matrix {
+	return p.EndTransform
+}
+
+func (p *Plane) SetEndTransform(m Matrix) {
+	p.EndTransform = &m
+}
+
 func (p *Plane) GetSavedRay() Ray {
 	return p.SavedRay
 }
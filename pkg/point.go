@@ -0,0 +1,64 @@
+package goray
+
+import "fmt"
+
+// Point is a position in space. Points can be moved by a Vector and
+// subtracted from each other, but not added, scaled or normalized.
+type Point Tuple
+
+func NewPoint(x, y, z float64) Point {
+	return Point{x: x, y: y, z: z, w: 1}
+}
+
+func (p Point) X() float64 {
+	return p.x
+}
+
+func (p Point) Y() float64 {
+	return p.y
+}
+
+func (p Point) Z() float64 {
+	return p.z
+}
+
+func (p Point) Add(v Vector) Point {
+	return NewPoint(p.x+v.x, p.y+v.y, p.z+v.z)
+}
+
+// Sub gives the vector from q to p.
+func (p Point) Sub(q Point) Vector {
+	return NewVector(p.x-q.x, p.y-q.y, p.z-q.z)
+}
+
+func (p Point) ApproxEqual(q Point, epsilon float64) bool {
+	return Tuple(p).ApproxEqual(Tuple(q), epsilon)
+}
+
+func (p Point) Min(q Point) Point {
+	return Point(Tuple(p).Min(Tuple(q)))
+}
+
+func (p Point) Max(q Point) Point {
+	return Point(Tuple(p).Max(Tuple(q)))
+}
+
+func (p Point) Lerp(q Point, t float64) Point {
+	return p.Add(q.Sub(p).Mul(t))
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("point(%g, %g, %g)", p.x, p.y, p.z)
+}
+
+func (p Point) MarshalJSON() ([]byte, error) {
+	return marshalTriple(p.x, p.y, p.z)
+}
+
+func (p *Point) UnmarshalJSON(data []byte) error {
+	x, y, z, err := unmarshalTriple(data, "point")
+	if err == nil {
+		*p = NewPoint(x, y, z)
+	}
+	return err
+}
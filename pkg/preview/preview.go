@@ -0,0 +1,274 @@
+// Package preview serves a live view of a scene file to a browser. The scene
+// is rendered tile by tile and each tile is streamed to the page over
+// Server-Sent Events as soon as it is finished. Whenever the file changes on
+// disk the render in progress is abandoned and a new one starts.
+package preview
+
+import (
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	g "github.com/mikowitz/goray/pkg"
+	"github.com/mikowitz/goray/pkg/distributed"
+)
+
+//go:embed index.html
+var indexPage []byte
+
+// subscriberBuffer is how many events a browser may fall behind before it
+// is dropped. EventSource reconnects on its own and is sent the whole frame
+// again.
+const subscriberBuffer = 256
+
+type event struct {
+	name string
+	data []byte
+}
+
+type frameEvent struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// tileEvent carries the tile's pixels as base64 encoded 8-bit RGBA, ready
+// for a canvas ImageData.
+type tileEvent struct {
+	distributed.Tile
+	Pixels string `json:"pixels"`
+}
+
+type failedEvent struct {
+	Message string `json:"message"`
+}
+
+// Preview watches Path and renders Frame of it whenever it changes, checking
+// the file every Poll. Saving the file without changing the scene does not
+// start a new render, and when only the post-processing changed the last
+// render is graded again rather than traced from scratch. Otherwise the
+// objects that did not change keep what was prepared for them last time.
+type Preview struct {
+	Path     string
+	Frame    int
+	TileSize int
+	Poll     time.Duration
+
+	mu          sync.Mutex
+	history     []event
+	subscribers map[chan event]bool
+
+	// Only touched by render, which Run never runs twice at once.
+	cache    *g.SceneCache
+	rendered *g.Scene
+	image    g.Canvas
+	// prepared is the last scene rendered, finished or not, with its world
+	// prepared.
+	prepared *g.Scene
+}
+
+func NewPreview(path string) *Preview {
+	return &Preview{
+		Path:        path,
+		TileSize:    16,
+		Poll:        250 * time.Millisecond,
+		subscribers: map[chan event]bool{},
+		cache:       g.NewSceneCache(),
+	}
+}
+
+func (p *Preview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(indexPage)
+	case "/events":
+		p.stream(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// stream sends the events of the current frame so far and then every new
+// event until the browser goes away or falls too far behind.
+func (p *Preview) stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	history, events := p.subscribe()
+	defer p.unsubscribe(events)
+
+	for _, e := range history {
+		writeEvent(w, e)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			writeEvent(w, e)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e event) {
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.name, e.data)
+}
+
+func (p *Preview) subscribe() ([]event, chan event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	events := make(chan event, subscriberBuffer)
+	p.subscribers[events] = true
+	return append([]event(nil), p.history...), events
+}
+
+func (p *Preview) unsubscribe(events chan event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.subscribers[events] {
+		delete(p.subscribers, events)
+		close(events)
+	}
+}
+
+// publish records an event in the current frame's history and sends it to
+// every subscriber. A frame or failed event starts a new history.
+func (p *Preview) publish(name string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	e := event{name: name, data: data}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if name == "frame" || name == "failed" {
+		p.history = nil
+	}
+	p.history = append(p.history, e)
+	for events := range p.subscribers {
+		select {
+		case events <- e:
+		default:
+			delete(p.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+// Run watches the scene file and re-renders it each time it changes until
+// ctx is done.
+func (p *Preview) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.Poll)
+	defer ticker.Stop()
+
+	var lastInfo os.FileInfo
+	var lastErr string
+	cancel := func() {}
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	for {
+		info, err := os.Stat(p.Path)
+		switch {
+		case err != nil:
+			if err.Error() != lastErr {
+				lastErr, lastInfo = err.Error(), nil
+				cancel()
+				wg.Wait()
+				p.rendered = nil
+				p.publish("failed", failedEvent{Message: err.Error()})
+			}
+		case lastInfo == nil || !info.ModTime().Equal(lastInfo.ModTime()) || info.Size() != lastInfo.Size():
+			lastErr, lastInfo = "", info
+			cancel()
+			wg.Wait()
+
+			renderCtx, cancelRender := context.WithCancel(ctx)
+			cancel = cancelRender
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.render(renderCtx)
+			}()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Preview) render(ctx context.Context) {
+	scene, err := p.cache.LoadSceneFile(p.Path)
+	if err != nil {
+		p.rendered = nil
+		p.publish("failed", failedEvent{Message: err.Error()})
+		return
+	}
+	scene.World, scene.Camera = scene.Frame(p.Frame)
+	c := scene.Camera
+
+	if p.rendered != nil {
+		diff := g.DiffScenes(*p.rendered, scene)
+		if !diff.Changed() {
+			return
+		}
+		if !diff.NeedsRender() {
+			p.rendered = &scene
+			p.publish("frame", frameEvent{Width: c.Width, Height: c.Height})
+			p.publishTile(distributed.Tile{Width: c.Width, Height: c.Height}, p.image, scene.Post)
+			p.publish("done", struct{}{})
+			return
+		}
+	}
+
+	p.rendered = nil
+	world := scene.World.Prepare()
+	if p.prepared != nil {
+		world = scene.World.PrepareChanged(p.prepared.World, g.DiffScenes(*p.prepared, scene))
+	}
+	prepared := scene
+	prepared.World = world
+	p.prepared = &prepared
+
+	image := g.NewCanvas(c.Width, c.AspectRatio)
+	p.publish("frame", frameEvent{Width: c.Width, Height: c.Height})
+	for _, t := range distributed.SplitTiles(c.Width, c.Height, p.TileSize) {
+		if ctx.Err() != nil {
+			return
+		}
+		tile := c.RenderTile(world, t.X, t.Y, t.Width, t.Height)
+		image.Paste(tile, t.X, t.Y)
+		p.publishTile(t, tile, scene.Post)
+	}
+	p.rendered, p.image = &scene, image
+	p.publish("done", struct{}{})
+}
+
+func (p *Preview) publishTile(t distributed.Tile, tile g.Canvas, post g.PostProcess) {
+	pixels := tile.PostProcess(post).ToImage().Pix
+	p.publish("tile", tileEvent{Tile: t, Pixels: base64.StdEncoding.EncodeToString(pixels)})
+}
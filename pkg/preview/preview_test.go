@@ -0,0 +1,177 @@
+package preview
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sceneDocument(width int) string {
+	return gradedSceneDocument(width, 0)
+}
+
+func gradedSceneDocument(width int, exposure float64) string {
+	return fmt.Sprintf(`{
+		"camera": {
+			"width": %d, "aspect_ratio": 2, "field_of_view": 1.0471975512,
+			"from": [0, 1.5, -5], "to": [0, 1, 0], "up": [0, 1, 0]
+		},
+		"post": {"exposure": %g},
+		"light": {"position": [-10, 10, -10], "intensity": [1, 1, 1]},
+		"objects": [{"type": "sphere", "transform": [{"translate": [0, 1, 0]}]}]
+	}`, width, exposure)
+}
+
+type sseReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r sseReader) next(t *testing.T) (string, string) {
+	var name, data string
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "" && name != "":
+			return name, data
+		}
+	}
+	t.Fatalf("event stream ended: %v", r.scanner.Err())
+	return "", ""
+}
+
+// untilDone reads a whole frame, returning its size and how many pixels its
+// tiles covered.
+func (r sseReader) untilDone(t *testing.T) (frameEvent, int) {
+	frame, pixels, _ := r.untilDoneCountingTiles(t)
+	return frame, pixels
+}
+
+func (r sseReader) untilDoneCountingTiles(t *testing.T) (frameEvent, int, int) {
+	var frame frameEvent
+	pixels, tiles := 0, 0
+	for {
+		name, data := r.next(t)
+		switch name {
+		case "frame":
+			assert.NoError(t, json.Unmarshal([]byte(data), &frame))
+			pixels = 0
+		case "tile":
+			var tile tileEvent
+			assert.NoError(t, json.Unmarshal([]byte(data), &tile))
+			rgba, err := base64.StdEncoding.DecodeString(tile.Pixels)
+			assert.NoError(t, err)
+			assert.Len(t, rgba, tile.Width*tile.Height*4)
+			pixels += tile.Width * tile.Height
+			tiles++
+		case "done":
+			return frame, pixels, tiles
+		case "failed":
+			t.Fatalf("render failed: %s", data)
+		}
+	}
+}
+
+func startPreview(t *testing.T, path string) (*httptest.Server, context.CancelFunc) {
+	p := NewPreview(path)
+	p.TileSize = 8
+	p.Poll = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+
+	server := httptest.NewServer(p)
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		server.Close()
+	})
+	return server, cancel
+}
+
+func connect(t *testing.T, server *httptest.Server) sseReader {
+	resp, err := http.Get(server.URL + "/events")
+	assert.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	assert.Equal(t, resp.Header.Get("Content-Type"), "text/event-stream")
+	return sseReader{bufio.NewScanner(resp.Body)}
+}
+
+func TestServesTheViewer(t *testing.T) {
+	server := httptest.NewServer(NewPreview("scene.json"))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	page, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(page), `new EventSource("/events")`)
+}
+
+func TestStreamsTilesAndRerendersOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scene.json")
+	assert.NoError(t, os.WriteFile(path, []byte(sceneDocument(20)), 0o644))
+
+	server, _ := startPreview(t, path)
+	events := connect(t, server)
+
+	frame, pixels := events.untilDone(t)
+	assert.Equal(t, frame, frameEvent{Width: 20, Height: 10})
+	assert.Equal(t, pixels, 200)
+
+	t.Run("a late browser is sent the finished frame", func(t *testing.T) {
+		frame, pixels := connect(t, server).untilDone(t)
+		assert.Equal(t, frame.Width, 20)
+		assert.Equal(t, pixels, 200)
+	})
+
+	t.Run("editing the file starts a new render", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(path, []byte(sceneDocument(30)), 0o644))
+		frame, pixels := events.untilDone(t)
+		assert.Equal(t, frame, frameEvent{Width: 30, Height: 15})
+		assert.Equal(t, pixels, 450)
+	})
+
+	t.Run("saving an unchanged scene does not render it again", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(path, []byte(sceneDocument(30)+"\n\n"), 0o644))
+		time.Sleep(50 * time.Millisecond)
+
+		// Only grading changes, so the next frame is the last render
+		// graded again and sent as a single tile.
+		assert.NoError(t, os.WriteFile(path, []byte(gradedSceneDocument(30, 1)), 0o644))
+		frame, pixels, tiles := events.untilDoneCountingTiles(t)
+		assert.Equal(t, frame, frameEvent{Width: 30, Height: 15})
+		assert.Equal(t, pixels, 450)
+		assert.Equal(t, tiles, 1)
+	})
+
+	t.Run("a broken file is reported", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(path, []byte(`{"camera": {`), 0o644))
+		for {
+			name, data := events.next(t)
+			if name == "failed" {
+				assert.Contains(t, data, "scene")
+				break
+			}
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package goray
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+// Accumulator sums the samples of a progressive render. Every pass adds one
+// sample to each pixel, so the image so far is the sum divided by Passes.
+// SceneHash identifies the world and camera the samples were taken from.
+type Accumulator struct {
+	SceneHash     [32]byte
+	Width, Height int
+	Passes        int
+	Sum           []Color
+}
+
+func NewAccumulator(w World, c Camera) Accumulator {
+	a := blankAccumulator(c.Width, c.Height)
+	a.SceneHash = HashScene(w, c)
+	return a
+}
+
+func blankAccumulator(width, height int) Accumulator {
+	return Accumulator{Width: width, Height: height, Sum: make([]Color, width*height)}
+}
+
+func (a Accumulator) Image() Canvas {
+	image := blankCanvas(a.Width, a.Height)
+	if a.Passes == 0 {
+		return image
+	}
+	for i, sum := range a.Sum {
+		image.Pixels[i] = sum.Div(float64(a.Passes))
+	}
+	return image
+}
+
+var accumulatorMagic = [4]byte{'G', 'A', 'C', 'C'}
+
+type accumulatorHeader struct {
+	Magic                 [4]byte
+	SceneHash             [32]byte
+	Width, Height, Passes uint32
+}
+
+// Save writes the accumulator in a small binary format: a header with its
+// scene hash, size and pass count followed by the little-endian float64 RGB
+// sums of each pixel, row by row.
+func (a Accumulator) Save(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	header := accumulatorHeader{accumulatorMagic, a.SceneHash, uint32(a.Width), uint32(a.Height), uint32(a.Passes)}
+	if err := binary.Write(bw, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	for _, sum := range a.Sum {
+		if err := binary.Write(bw, binary.LittleEndian, [3]float64{sum.x, sum.y, sum.z}); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func LoadAccumulator(r io.Reader) (Accumulator, error) {
+	br := bufio.NewReader(r)
+	var header accumulatorHeader
+	if err := binary.Read(br, binary.LittleEndian, &header); err != nil {
+		return Accumulator{}, err
+	}
+	if header.Magic != accumulatorMagic {
+		return Accumulator{}, errors.New("accumulator: bad signature")
+	}
+
+	width, height := int(header.Width), int(header.Height)
+	if err := checkImageSize(width, height); err != nil {
+		return Accumulator{}, fmt.Errorf("accumulator: %w", err)
+	}
+
+	a := blankAccumulator(width, height)
+	a.SceneHash = header.SceneHash
+	a.Passes = int(header.Passes)
+	for i := range a.Sum {
+		var rgb [3]float64
+		if err := binary.Read(br, binary.LittleEndian, &rgb); err != nil {
+			return Accumulator{}, err
+		}
+		a.Sum[i] = NewColor(rgb[0], rgb[1], rgb[2])
+	}
+	return a, nil
+}
+
+// SaveFile writes the accumulator beside path and then renames it into
+// place, as Checkpoint.SaveFile does.
+func (a Accumulator) SaveFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if err := a.Save(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func LoadAccumulatorFile(path string) (Accumulator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Accumulator{}, err
+	}
+	defer f.Close()
+	return LoadAccumulator(f)
+}
+
+// RenderProgressive adds passes more samples to every pixel of acc, one pass
+// over the image at a time, so the picture sharpens as it goes rather than
+// appearing a scanline at a time. After every pass snapshot, if it is not
+// nil, is called with the number of passes acc now holds and the image so
+// far. Each pass draws from its own random stream, seeded by the camera's
+// Seed and the pass number, so a render resumed from a saved accumulator
+// finishes with the same image as one that ran uninterrupted. An accumulator
+// from any other world or camera returns ErrSceneChanged.
+func (c Camera) RenderProgressive(w World, acc *Accumulator, passes int, snapshot func(pass int, image Canvas)) error {
+	if acc.Width != c.Width || acc.Height != c.Height {
+		return errors.New("accumulator does not match the camera's resolution")
+	}
+	if acc.SceneHash != HashScene(w, c) {
+		return ErrSceneChanged
+	}
+
+	integrator := c.Integrator
+	if integrator == nil {
+		integrator = NewWhittedIntegrator()
+	}
+	w = w.prepared()
+
+	bar := progressbar.NewOptions(passes,
+		progressbar.OptionSetWriter(os.Stderr),
+	)
+
+	for range passes {
+		rng := rand.New(rand.NewPCG(c.Seed, uint64(acc.Passes)+1))
+		w.Rand = rng
+		for y := range c.Height {
+			for x := range c.Width {
+				i := y*c.Width + x
+				acc.Sum[i] = acc.Sum[i].Add(integrator.Li(w, c.sampleRay(x, y, rng), rng))
+			}
+		}
+		acc.Passes++
+
+		if snapshot != nil {
+			snapshot(acc.Passes, acc.Image())
+		}
+		if err := bar.Add(1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
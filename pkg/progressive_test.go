@@ -0,0 +1,135 @@
+package goray
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func progressiveScene() (World, Camera) {
+	w := defaultWorld()
+	c := NewCamera(6, 1.5, math.Pi/2)
+	c.Transform = NewViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+	c.Integrator = NewPathTracer()
+	c.Seed = 3
+	return w, c
+}
+
+func TestAccumulator(t *testing.T) {
+	t.Run("an empty accumulator is black", func(t *testing.T) {
+		a := blankAccumulator(2, 1)
+		assert.Equal(t, a.Image().Pixels, []Color{{}, {}})
+	})
+
+	t.Run("the image is the mean of the passes", func(t *testing.T) {
+		a := blankAccumulator(1, 1)
+		a.Sum[0] = NewColor(1, 2, 3)
+		a.Passes = 4
+		assert.Equal(t, a.Image().At(0, 0), NewColor(0.25, 0.5, 0.75))
+	})
+
+	t.Run("round trips through Save and LoadAccumulator", func(t *testing.T) {
+		a := NewAccumulator(progressiveScene())
+		a.Passes = 7
+		a.Sum[3] = NewColor(0.1, 12.5, 1e-9)
+
+		var buf bytes.Buffer
+		assert.NoError(t, a.Save(&buf))
+		loaded, err := LoadAccumulator(&buf)
+		assert.NoError(t, err)
+		assert.Equal(t, loaded, a)
+	})
+
+	t.Run("rejects other files", func(t *testing.T) {
+		_, err := LoadAccumulator(bytes.NewReader([]byte("PF\n1 1\n-1\n0000000000000")))
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects impossible sizes", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, blankAccumulator(0, 0).Save(&buf))
+		_, err := LoadAccumulator(&buf)
+		assert.ErrorContains(t, err, "image size")
+	})
+
+	t.Run("saves files whole", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "render.acc")
+		a := NewAccumulator(progressiveScene())
+		a.Passes = 2
+		assert.NoError(t, a.SaveFile(path))
+		assert.NoError(t, a.SaveFile(path))
+
+		loaded, err := LoadAccumulatorFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, loaded, a)
+		entries, err := os.ReadDir(filepath.Dir(path))
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+}
+
+func TestRenderProgressive(t *testing.T) {
+	t.Run("calls snapshot after every pass", func(t *testing.T) {
+		w, c := progressiveScene()
+		acc := NewAccumulator(w, c)
+
+		var passes []int
+		err := c.RenderProgressive(w, &acc, 3, func(pass int, image Canvas) {
+			passes = append(passes, pass)
+			assert.Equal(t, image.Width, c.Width)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, passes, []int{1, 2, 3})
+		assert.Equal(t, acc.Passes, 3)
+	})
+
+	t.Run("a resumed render matches an uninterrupted one", func(t *testing.T) {
+		w, c := progressiveScene()
+		full := NewAccumulator(w, c)
+		assert.NoError(t, c.RenderProgressive(w, &full, 4, nil))
+
+		partial := NewAccumulator(w, c)
+		assert.NoError(t, c.RenderProgressive(w, &partial, 2, nil))
+		var buf bytes.Buffer
+		assert.NoError(t, partial.Save(&buf))
+		resumed, err := LoadAccumulator(&buf)
+		assert.NoError(t, err)
+		assert.NoError(t, c.RenderProgressive(w, &resumed, 2, nil))
+
+		assert.Equal(t, resumed, full)
+	})
+
+	t.Run("converges on the same image as a multi-sample render", func(t *testing.T) {
+		w, c := progressiveScene()
+		c.Integrator = NewWhittedIntegrator()
+		acc := NewAccumulator(w, c)
+		assert.NoError(t, c.RenderProgressive(w, &acc, 64, nil))
+
+		c.SamplesPerPixel = 64
+		expected := c.Render(w)
+		assert.Less(t, RMSE(acc.Image(), expected), 0.05)
+	})
+
+	t.Run("rejects an accumulator of the wrong size", func(t *testing.T) {
+		w, c := progressiveScene()
+		acc := blankAccumulator(1, 1)
+		assert.Error(t, c.RenderProgressive(w, &acc, 1, nil))
+	})
+
+	t.Run("refuses an accumulator from another scene", func(t *testing.T) {
+		w, c := progressiveScene()
+		acc := NewAccumulator(w, c)
+		assert.NoError(t, c.RenderProgressive(w, &acc, 1, nil))
+
+		c.Seed++
+		assert.ErrorIs(t, c.RenderProgressive(w, &acc, 1, nil), ErrSceneChanged)
+		w.LightSource.Position = NewPoint(0, 10, 0)
+		c.Seed--
+		assert.ErrorIs(t, c.RenderProgressive(w, &acc, 1, nil), ErrSceneChanged)
+		assert.Equal(t, acc.Passes, 1)
+	})
+}
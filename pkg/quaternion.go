@@ -0,0 +1,127 @@
+package goray
+
+import (
+	"fmt"
+	"math"
+)
+
+// Quaternion is a rotation stored as the unit quaternion W + Xi + Yj + Zk.
+// Unlike a rotation matrix it can be interpolated with Slerp without
+// shrinking or shearing what it rotates.
+type Quaternion struct {
+	W, X, Y, Z float64
+}
+
+func IdentityQuaternion() Quaternion {
+	return Quaternion{W: 1}
+}
+
+// AxisAngle is a rotation of radians about axis, turning the same way as
+// RotationX, RotationY and RotationZ do about theirs.
+func AxisAngle(axis Vector, radians float64) Quaternion {
+	axis = axis.Normalize()
+	s := math.Sin(radians / 2)
+	return Quaternion{W: math.Cos(radians / 2), X: axis.x * s, Y: axis.y * s, Z: axis.z * s}
+}
+
+// QuaternionFromMatrix reads the rotation out of the upper 3x3 of m, which
+// must be a pure rotation.
+func QuaternionFromMatrix(m Matrix) Quaternion {
+	r00, r01, r02 := m[0], m[1], m[2]
+	r10, r11, r12 := m[4], m[5], m[6]
+	r20, r21, r22 := m[8], m[9], m[10]
+
+	var q Quaternion
+	switch trace := r00 + r11 + r22; {
+	case trace > 0:
+		s := math.Sqrt(trace+1) * 2
+		q = Quaternion{W: s / 4, X: (r21 - r12) / s, Y: (r02 - r20) / s, Z: (r10 - r01) / s}
+	case r00 > r11 && r00 > r22:
+		s := math.Sqrt(1+r00-r11-r22) * 2
+		q = Quaternion{W: (r21 - r12) / s, X: s / 4, Y: (r01 + r10) / s, Z: (r02 + r20) / s}
+	case r11 > r22:
+		s := math.Sqrt(1+r11-r00-r22) * 2
+		q = Quaternion{W: (r02 - r20) / s, X: (r01 + r10) / s, Y: s / 4, Z: (r12 + r21) / s}
+	default:
+		s := math.Sqrt(1+r22-r00-r11) * 2
+		q = Quaternion{W: (r10 - r01) / s, X: (r02 + r20) / s, Y: (r12 + r21) / s, Z: s / 4}
+	}
+	return q.Normalize()
+}
+
+// Mul composes two rotations. Like Matrix.Mul, q.Mul(r) rotates by r first
+// and then by q.
+func (q Quaternion) Mul(r Quaternion) Quaternion {
+	return Quaternion{
+		W: q.W*r.W - q.X*r.X - q.Y*r.Y - q.Z*r.Z,
+		X: q.W*r.X + q.X*r.W + q.Y*r.Z - q.Z*r.Y,
+		Y: q.W*r.Y - q.X*r.Z + q.Y*r.W + q.Z*r.X,
+		Z: q.W*r.Z + q.X*r.Y - q.Y*r.X + q.Z*r.W,
+	}
+}
+
+func (q Quaternion) Dot(r Quaternion) float64 {
+	return q.W*r.W + q.X*r.X + q.Y*r.Y + q.Z*r.Z
+}
+
+func (q Quaternion) Normalize() Quaternion {
+	n := math.Sqrt(q.Dot(q))
+	return Quaternion{W: q.W / n, X: q.X / n, Y: q.Y / n, Z: q.Z / n}
+}
+
+func (q Quaternion) Conjugate() Quaternion {
+	return Quaternion{W: q.W, X: -q.X, Y: -q.Y, Z: -q.Z}
+}
+
+// AxisAngle returns the axis q rotates about and by how much. The identity
+// rotation has no axis of its own and returns the x axis.
+func (q Quaternion) AxisAngle() (Vector, float64) {
+	s := math.Sqrt(q.X*q.X + q.Y*q.Y + q.Z*q.Z)
+	if s == 0 {
+		return NewVector(1, 0, 0), 0
+	}
+	return NewVector(q.X/s, q.Y/s, q.Z/s), 2 * math.Atan2(s, q.W)
+}
+
+func (q Quaternion) Rotate(v Vector) Vector {
+	return q.Matrix().MulVector(v)
+}
+
+func (q Quaternion) Matrix() Matrix {
+	w, x, y, z := q.W, q.X, q.Y, q.Z
+	return Matrix{
+		1 - 2*(y*y+z*z), 2 * (x*y - w*z), 2 * (x*z + w*y), 0,
+		2 * (x*y + w*z), 1 - 2*(x*x+z*z), 2 * (y*z - w*x), 0,
+		2 * (x*z - w*y), 2 * (y*z + w*x), 1 - 2*(x*x+y*y), 0,
+		0, 0, 0, 1,
+	}
+}
+
+// Slerp turns from q towards r at a constant rate, taking the shorter way
+// round, giving q at 0 and r at 1.
+func (q Quaternion) Slerp(r Quaternion, t float64) Quaternion {
+	cos := q.Dot(r)
+	if cos < 0 {
+		r = Quaternion{W: -r.W, X: -r.X, Y: -r.Y, Z: -r.Z}
+		cos = -cos
+	}
+
+	// Nearly parallel rotations divide by almost nothing below, and are close
+	// enough that a normalized straight line between them is just as good.
+	a, b := 1-t, t
+	if cos < 0.9995 {
+		angle := math.Acos(cos)
+		a = math.Sin((1-t)*angle) / math.Sin(angle)
+		b = math.Sin(t*angle) / math.Sin(angle)
+	}
+	return Quaternion{
+		W: a*q.W + b*r.W,
+		X: a*q.X + b*r.X,
+		Y: a*q.Y + b*r.Y,
+		Z: a*q.Z + b*r.Z,
+	}.Normalize()
+}
+
+func (q Quaternion) String() string {
+	return fmt.Sprintf("quaternion(%g, %g, %g, %g)", q.W, q.X, q.Y, q.Z)
+}
@@ -0,0 +1,92 @@
+package goray
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func QuaternionsEqual(q, r Quaternion) bool {
+	// q and -q are the same rotation.
+	return math.Abs(math.Abs(q.Dot(r))-1) < 0.00001
+}
+
+func TestAxisAngle(t *testing.T) {
+	testCases := map[string]struct {
+		rotation Quaternion
+		expected Matrix
+	}{
+		"about x": {AxisAngle(NewVector(1, 0, 0), math.Pi/3), RotationX(math.Pi / 3)},
+		"about y": {AxisAngle(NewVector(0, 2, 0), -math.Pi/4), RotationY(-math.Pi / 4)},
+		"about z": {AxisAngle(NewVector(0, 0, 1), 2), RotationZ(2)},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			assert.True(t, MatricesEqual(tc.rotation.Matrix(), tc.expected))
+		})
+	}
+
+	t.Run("reading back the axis and angle", func(t *testing.T) {
+		axis, angle := AxisAngle(NewVector(0, 3, 4), 1.2).AxisAngle()
+		assert.True(t, TuplesEqual(axis, NewVector(0, 0.6, 0.8)))
+		assert.InDelta(t, angle, 1.2, 0.00001)
+
+		_, angle = IdentityQuaternion().AxisAngle()
+		assert.Equal(t, angle, 0.0)
+	})
+}
+
+func TestQuaternionMul(t *testing.T) {
+	q := AxisAngle(NewVector(1, 0, 0), math.Pi/2)
+	r := AxisAngle(NewVector(0, 1, 0), math.Pi/3)
+
+	assert.True(t, MatricesEqual(q.Mul(r).Matrix(), RotationX(math.Pi/2).Mul(RotationY(math.Pi/3))))
+	assert.True(t, QuaternionsEqual(q.Mul(q.Conjugate()), IdentityQuaternion()))
+	assert.True(t, TuplesEqual(q.Rotate(NewVector(0, 1, 0)), NewVector(0, 0, 1)))
+}
+
+func TestQuaternionFromMatrix(t *testing.T) {
+	// Each of these lands in a different branch, by which diagonal entry is
+	// largest.
+	testCases := map[string]Quaternion{
+		"identity":            IdentityQuaternion(),
+		"half turn about x":   AxisAngle(NewVector(1, 0, 0), math.Pi),
+		"half turn about y":   AxisAngle(NewVector(0, 1, 0), math.Pi),
+		"half turn about z":   AxisAngle(NewVector(0, 0, 1), math.Pi),
+		"arbitrary":           AxisAngle(NewVector(1, -2, 3), 2.5),
+		"nearly a whole turn": AxisAngle(NewVector(-1, 1, 1), 2*math.Pi-0.1),
+	}
+
+	for description, q := range testCases {
+		t.Run(description, func(t *testing.T) {
+			assert.True(t, QuaternionsEqual(QuaternionFromMatrix(q.Matrix()), q))
+		})
+	}
+}
+
+func TestQuaternionSlerp(t *testing.T) {
+	a := IdentityQuaternion()
+	b := AxisAngle(NewVector(0, 1, 0), math.Pi/2)
+
+	t.Run("turns at a constant rate", func(t *testing.T) {
+		assert.True(t, QuaternionsEqual(a.Slerp(b, 0.5), AxisAngle(NewVector(0, 1, 0), math.Pi/4)))
+		assert.True(t, QuaternionsEqual(a.Slerp(b, 0.25), AxisAngle(NewVector(0, 1, 0), math.Pi/8)))
+	})
+
+	t.Run("hits both ends", func(t *testing.T) {
+		assert.True(t, QuaternionsEqual(a.Slerp(b, 0), a))
+		assert.True(t, QuaternionsEqual(a.Slerp(b, 1), b))
+	})
+
+	t.Run("takes the shorter way round", func(t *testing.T) {
+		c := AxisAngle(NewVector(0, 1, 0), 3*math.Pi/2)
+		assert.True(t, QuaternionsEqual(a.Slerp(c, 0.5), AxisAngle(NewVector(0, 1, 0), -math.Pi/4)))
+	})
+
+	t.Run("between nearly equal rotations", func(t *testing.T) {
+		c := AxisAngle(NewVector(0, 1, 0), 0.001)
+		assert.True(t, QuaternionsEqual(a.Slerp(c, 0.5), AxisAngle(NewVector(0, 1, 0), 0.0005)))
+	})
+}
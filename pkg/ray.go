@@ -3,24 +3,36 @@ package goray
 type Ray struct {
 	Origin    Point
 	Direction Vector
+	Time      float64
 }
 
 func NewRay(origin Point, direction Vector) Ray {
 	return Ray{Origin: origin, Direction: direction}
 }
 
+func NewRayAtTime(origin Point, direction Vector, time float64) Ray {
+	return Ray{Origin: origin, Direction: direction, Time: time}
+}
+
 func (r Ray) At(t float64) Point {
 	return r.Origin.Add(r.Direction.Mul(t))
 }
 
+// Intersect finds where ray meets shape. A moving shape that is flattened
+// to nothing at the ray's time, as one mirrored between its start and end
+// is halfway through, cannot be hit then.
 func (ray Ray) Intersect(shape Shape) Intersections {
-	ray2 := ray.Transform(shape.GetTransform().Inverse())
-	return shape.LocalIntersect(ray2)
+	inverse, ok := TransformAt(shape, ray.Time).TryInverse()
+	if !ok {
+		return Intersections{}
+	}
+	return shape.LocalIntersect(ray.Transform(inverse))
 }
 
 func (ray Ray) Transform(m Matrix) Ray {
-	return NewRay(
-		m.Mult(ray.Origin),
-		m.Mult(ray.Direction),
+	return NewRayAtTime(
+		m.MulPoint(ray.Origin),
+		m.MulVector(ray.Direction),
+		ray.Time,
 	)
 }
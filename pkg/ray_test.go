@@ -1,6 +1,7 @@
 package goray
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -115,3 +116,53 @@ func TestTransformingARay(t *testing.T) {
 		assert.True(t, TuplesEqual(r2.Direction, NewVector(0, 3, 0)))
 	})
 }
+
+func TestRayTime(t *testing.T) {
+	t.Run("is preserved by transformations", func(t *testing.T) {
+		r := NewRayAtTime(NewPoint(1, 2, 3), NewVector(0, 1, 0), 0.25)
+		r2 := r.Transform(Translation(3, 4, 5))
+
+		assert.Equal(t, r2.Time, 0.25)
+	})
+
+	t.Run("selects where a moving shape is intersected", func(t *testing.T) {
+		s := NewSphere()
+		s.SetEndTransform(Translation(0, 0, 2))
+
+		early := NewRayAtTime(NewPoint(0, 0, -5), NewVector(0, 0, 1), 0)
+		late := NewRayAtTime(NewPoint(0, 0, -5), NewVector(0, 0, 1), 1)
+
+		assert.Equal(t, early.Intersect(&s)[0].T, 4.0)
+		assert.Equal(t, late.Intersect(&s)[0].T, 6.0)
+	})
+
+	t.Run("hits a shape halfway through a half turn", func(t *testing.T) {
+		s := NewSphere()
+		s.SetTransform(Scaling(2, 1, 1))
+		s.SetEndTransform(RotationY(math.Pi).Mul(Scaling(2, 1, 1)))
+
+		// Turned a quarter, the sphere's long axis lies along z.
+		r := NewRayAtTime(NewPoint(0, 0, -5), NewVector(0, 0, 1), 0.5)
+		xs := r.Intersect(&s)
+		assert.Len(t, xs, 2)
+		assert.InDelta(t, xs[0].T, 3.0, 0.00001)
+	})
+
+	t.Run("misses a shape flattened at that time", func(t *testing.T) {
+		s := NewSphere()
+		s.SetEndTransform(Scaling(-1, 1, 1))
+
+		r := NewRayAtTime(NewPoint(0, 0, -5), NewVector(0, 0, 1), 0.5)
+		assert.Empty(t, r.Intersect(&s))
+	})
+
+	t.Run("is carried into computations", func(t *testing.T) {
+		s := NewSphere()
+		r := NewRayAtTime(NewPoint(0, 0, -5), NewVector(0, 0, 1), 0.75)
+		i := NewIntersection(4, &s)
+
+		comps := i.PrepareComputations(r, Intersections{i})
+
+		assert.Equal(t, comps.Time, 0.75)
+	})
+}
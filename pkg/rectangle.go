@@ -0,0 +1,68 @@
+package goray
+
+import "math"
+
+type Rectangle struct {
+	Material     Material
+	Transform    Matrix
+	EndTransform *Matrix
+	SavedRay     Ray
+}
+
+func NewRectangle() Rectangle {
+	return Rectangle{
+		Material:  NewMaterial(),
+		Transform: IdentityMatrix(),
+	}
+}
+
+func (r *Rectangle) GetMaterial() Material {
+	return r.Material
+}
+
+func (r *Rectangle) SetMaterial(m Material) {
+	r.Material = m
+}
+
+func (r *Rectangle) GetTransform() Matrix {
+	return r.Transform
+}
+
+func (r *Rectangle) SetTransform(m Matrix) {
+	r.Transform = m
+}
+
+func (r *Rectangle) GetEndTransform() *Matrix {
+	return r.EndTransform
+}
+
+func (r *Rectangle) SetEndTransform(m Matrix) {
+	r.EndTransform = &m
+}
+
+func (r *Rectangle) GetSavedRay() Ray {
+	return r.SavedRay
+}
+
+func (r *Rectangle) LocalIntersect(ray Ray) Intersections {
+	r.SavedRay = ray
+	if math.Abs(ray.Direction.y) < 0.00001 {
+		return Intersections{}
+	}
+	t := -ray.Origin.y / ray.Direction.y
+	p := ray.At(t)
+	if math.Abs(p.x) > 1.0 || math.Abs(p.z) > 1.0 {
+		return Intersections{}
+	}
+	return Intersections{
+		NewIntersection(t, r),
+	}
+}
+
+func (r *Rectangle) LocalNormalAt(_ Point) Vector {
+	return NewVector(0, 1, 0)
+}
+
+func (r *Rectangle) SampleSurface(u, v float64) Point {
+	return NewPoint(2.0*u-1.0, 0, 2.0*v-1.0)
+}
@@ -0,0 +1,47 @@
+package goray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRectangleNormal(t *testing.T) {
+	r := NewRectangle()
+
+	assert.True(t, TuplesEqual(r.LocalNormalAt(NewPoint(0, 0, 0)), NewVector(0, 1, 0)))
+	assert.True(t, TuplesEqual(r.LocalNormalAt(NewPoint(0.5, 0, -0.5)), NewVector(0, 1, 0)))
+}
+
+func TestRectangleIntersection(t *testing.T) {
+	t.Run("with a parallel ray", func(t *testing.T) {
+		r := NewRectangle()
+		xs := r.LocalIntersect(NewRay(NewPoint(0, 10, 0), NewVector(0, 0, 1)))
+
+		assert.Empty(t, xs)
+	})
+
+	t.Run("inside its bounds", func(t *testing.T) {
+		r := NewRectangle()
+		xs := r.LocalIntersect(NewRay(NewPoint(0.5, 1, -0.5), NewVector(0, -1, 0)))
+
+		assert.Equal(t, len(xs), 1)
+		assert.Equal(t, xs[0].T, 1.0)
+		assert.Equal(t, xs[0].Object, &r)
+	})
+
+	t.Run("outside its bounds", func(t *testing.T) {
+		r := NewRectangle()
+		xs := r.LocalIntersect(NewRay(NewPoint(1.5, 1, 0), NewVector(0, -1, 0)))
+
+		assert.Empty(t, xs)
+	})
+}
+
+func TestRectangleSampleSurface(t *testing.T) {
+	r := NewRectangle()
+
+	assert.True(t, TuplesEqual(r.SampleSurface(0, 0), NewPoint(-1, 0, -1)))
+	assert.True(t, TuplesEqual(r.SampleSurface(0.5, 0.5), NewPoint(0, 0, 0)))
+	assert.True(t, TuplesEqual(r.SampleSurface(1, 1), NewPoint(1, 0, 1)))
+}
@@ -0,0 +1,862 @@
+package goray
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scene bundles everything needed to render one or more frames: the world,
+// the camera looking at it, an optional animation over FirstFrame to
+// LastFrame and the post-processing applied to each rendered frame.
+type Scene struct {
+	World                 World
+	Camera                Camera
+	Animation             Animation
+	FirstFrame, LastFrame int
+	Post                  PostProcess
+}
+
+// LoadSceneFile reads a JSON scene document from path. Relative paths inside
+// the document, such as environment maps, are resolved against the directory
+// the file is in.
+func LoadSceneFile(path string) (Scene, error) {
+	return (*SceneCache)(nil).LoadSceneFile(path)
+}
+
+func LoadScene(r io.Reader) (Scene, error) {
+	return loadScene(r, "", nil)
+}
+
+// LoadSceneWithoutFiles reads a scene document that may not refer to any
+// files, for documents from clients who should not be able to make the
+// process read whatever path they name.
+func LoadSceneWithoutFiles(r io.Reader) (Scene, error) {
+	var doc sceneDocument
+	if err := decodeScene(r, &doc); err != nil {
+		return Scene{}, err
+	}
+	loader := sceneLoader{objects: map[string]Shape{}, placements: map[string]Matrix{}, noFiles: true}
+	return loader.build(doc)
+}
+
+// SceneCache keeps the files a scene refers to, such as environment maps,
+// so loading the scene again after an edit only reads the ones that changed
+// on disk. A nil cache reads everything every time.
+type SceneCache struct {
+	mu     sync.Mutex
+	images map[string]cachedImage
+}
+
+type cachedImage struct {
+	modTime time.Time
+	size    int64
+	image   Canvas
+}
+
+func NewSceneCache() *SceneCache {
+	return &SceneCache{images: map[string]cachedImage{}}
+}
+
+func (sc *SceneCache) LoadSceneFile(path string) (Scene, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Scene{}, err
+	}
+	defer f.Close()
+	return loadScene(f, filepath.Dir(path), sc)
+}
+
+func (sc *SceneCache) loadHDR(path string) (Canvas, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Canvas{}, err
+	}
+	if sc != nil {
+		sc.mu.Lock()
+		cached, ok := sc.images[path]
+		sc.mu.Unlock()
+		if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+			return cached.image, nil
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Canvas{}, err
+	}
+	defer f.Close()
+	image, err := LoadHDR(f)
+	if err != nil {
+		return Canvas{}, err
+	}
+
+	if sc != nil {
+		sc.mu.Lock()
+		sc.images[path] = cachedImage{modTime: info.ModTime(), size: info.Size(), image: image}
+		sc.mu.Unlock()
+	}
+	return image, nil
+}
+
+func loadScene(r io.Reader, dir string, cache *SceneCache) (Scene, error) {
+	var doc sceneDocument
+	if err := decodeScene(r, &doc); err != nil {
+		return Scene{}, err
+	}
+	loader := sceneLoader{dir: dir, objects: map[string]Shape{}, placements: map[string]Matrix{}, cache: cache}
+	return loader.build(doc)
+}
+
+func decodeScene(r io.Reader, doc *sceneDocument) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(doc); err != nil {
+		return fmt.Errorf("scene: %w", err)
+	}
+	return nil
+}
+
+type vec3 [3]float64
+
+func (v vec3) point() Point {
+	return NewPoint(v[0], v[1], v[2])
+}
+
+func (v vec3) vector() Vector {
+	return NewVector(v[0], v[1], v[2])
+}
+
+func (v vec3) color() Color {
+	return NewColor(v[0], v[1], v[2])
+}
+
+type sceneDocument struct {
+	Camera     sceneCamera      `json:"camera"`
+	Light      *sceneLight      `json:"light,omitempty"`
+	AreaLights []sceneAreaLight `json:"area_lights,omitempty"`
+	Background *sceneBackground `json:"background,omitempty"`
+	IBLSamples int              `json:"ibl_samples,omitempty"`
+	Fog        *sceneFog        `json:"fog,omitempty"`
+	Volumes    []sceneVolume    `json:"volumes,omitempty"`
+	Objects    []sceneObject    `json:"objects"`
+	Animation  *sceneAnimation  `json:"animation,omitempty"`
+	Post       *scenePost       `json:"post,omitempty"`
+}
+
+type sceneCamera struct {
+	Width       int              `json:"width"`
+	AspectRatio float64          `json:"aspect_ratio"`
+	FieldOfView float64          `json:"field_of_view"`
+	From        *vec3            `json:"from,omitempty"`
+	To          *vec3            `json:"to,omitempty"`
+	Up          *vec3            `json:"up,omitempty"`
+	Transform   sceneTransform   `json:"transform,omitempty"`
+	Samples     int              `json:"samples,omitempty"`
+	Seed        uint64           `json:"seed,omitempty"`
+	Shutter     *[2]float64      `json:"shutter,omitempty"`
+	Integrator  *sceneIntegrator `json:"integrator,omitempty"`
+}
+
+type sceneIntegrator struct {
+	Type          string `json:"type"`
+	MaxDepth      *int   `json:"max_depth,omitempty"`
+	RouletteDepth *int   `json:"roulette_depth,omitempty"`
+}
+
+type scenePost struct {
+	Exposure float64 `json:"exposure,omitempty"`
+	ToneMap  string  `json:"tone_map,omitempty"`
+	SRGB     bool    `json:"srgb,omitempty"`
+}
+
+type sceneLight struct {
+	Position  vec3 `json:"position"`
+	Intensity vec3 `json:"intensity"`
+}
+
+type sceneAreaLight struct {
+	Object  string `json:"object"`
+	Samples int    `json:"samples"`
+}
+
+type sceneBackground struct {
+	Type              string   `json:"type"`
+	Color             *vec3    `json:"color,omitempty"`
+	Bottom            *vec3    `json:"bottom,omitempty"`
+	Top               *vec3    `json:"top,omitempty"`
+	SunDirection      *vec3    `json:"sun_direction,omitempty"`
+	SunColor          *vec3    `json:"sun_color,omitempty"`
+	SunAngularRadius  *float64 `json:"sun_angular_radius,omitempty"`
+	Zenith            *vec3    `json:"zenith,omitempty"`
+	Horizon           *vec3    `json:"horizon,omitempty"`
+	Ground            *vec3    `json:"ground,omitempty"`
+	GlowIntensity     *float64 `json:"glow_intensity,omitempty"`
+	GlowConcentration *float64 `json:"glow_concentration,omitempty"`
+	Path              string   `json:"path,omitempty"`
+	Intensity         *float64 `json:"intensity,omitempty"`
+	Rotation          float64  `json:"rotation,omitempty"`
+}
+
+type sceneFog struct {
+	Color   vec3    `json:"color"`
+	Density float64 `json:"density"`
+}
+
+type sceneVolume struct {
+	Boundary   sceneObject `json:"boundary"`
+	Absorption float64     `json:"absorption"`
+	Scattering float64     `json:"scattering"`
+	Color      *vec3       `json:"color,omitempty"`
+	Steps      int         `json:"steps,omitempty"`
+}
+
+type sceneObject struct {
+	Name         string         `json:"name,omitempty"`
+	Type         string         `json:"type"`
+	Center       *vec3          `json:"center,omitempty"`
+	Radius       *float64       `json:"radius,omitempty"`
+	Transform    sceneTransform `json:"transform,omitempty"`
+	EndTransform sceneTransform `json:"end_transform,omitempty"`
+	Material     *sceneMaterial `json:"material,omitempty"`
+}
+
+type sceneMaterial struct {
+	Model           string        `json:"model,omitempty"`
+	Color           *vec3         `json:"color,omitempty"`
+	Pattern         *scenePattern `json:"pattern,omitempty"`
+	Ambient         *float64      `json:"ambient,omitempty"`
+	Diffuse         *float64      `json:"diffuse,omitempty"`
+	Specular        *float64      `json:"specular,omitempty"`
+	Shininess       *float64      `json:"shininess,omitempty"`
+	Reflective      *float64      `json:"reflective,omitempty"`
+	Transparency    *float64      `json:"transparency,omitempty"`
+	RefractiveIndex *float64      `json:"refractive_index,omitempty"`
+	Metallic        *float64      `json:"metallic,omitempty"`
+	Roughness       *float64      `json:"roughness,omitempty"`
+	GlossySamples   *int          `json:"glossy_samples,omitempty"`
+	CastsShadow     *bool         `json:"casts_shadow,omitempty"`
+	Emission        *vec3         `json:"emission,omitempty"`
+}
+
+type scenePattern struct {
+	Type      string          `json:"type"`
+	Color     *vec3           `json:"color,omitempty"`
+	A         json.RawMessage `json:"a,omitempty"`
+	B         json.RawMessage `json:"b,omitempty"`
+	Transform sceneTransform  `json:"transform,omitempty"`
+}
+
+type sceneAnimation struct {
+	Frames [2]int       `json:"frames"`
+	Tracks []sceneTrack `json:"tracks"`
+}
+
+type sceneTrack struct {
+	Target string          `json:"target"`
+	Keys   []sceneKeyframe `json:"keys"`
+}
+
+type sceneKeyframe struct {
+	Frame  float64         `json:"frame"`
+	Value  json.RawMessage `json:"value"`
+	Easing string          `json:"easing,omitempty"`
+}
+
+// sceneTransform is a list of single-key operations such as
+// {"translate": [0, 1, 0]} or {"rotate_y": 1.5}. They are applied to the
+// object in the order they are listed, so the first operation listed is the
+// first one applied.
+type sceneTransform []map[string]json.RawMessage
+
+// matrix composes the operations. The first one is taken as it is rather
+// than multiplied into the identity, which would turn any -0 in it into 0,
+// so a single matrix operation loads back bit for bit.
+func (st sceneTransform) matrix() (Matrix, error) {
+	m := IdentityMatrix()
+	for i, op := range st {
+		if len(op) != 1 {
+			return Matrix{}, fmt.Errorf("scene: transform operations need exactly one key, got %d", len(op))
+		}
+		for name, args := range op {
+			n, err := transformOperation(name, args)
+			if err != nil {
+				return Matrix{}, err
+			}
+			if i == 0 {
+				m = n
+			} else {
+				m = n.Mul(m)
+			}
+		}
+	}
+	if _, ok := m.TryInverse(); !ok {
+		return Matrix{}, fmt.Errorf("scene: transform cannot be inverted")
+	}
+	return m, nil
+}
+
+func transformOperation(name string, args json.RawMessage) (Matrix, error) {
+	var err error
+	switch name {
+	case "translate":
+		var v vec3
+		if err = json.Unmarshal(args, &v); err == nil {
+			return Translation(v[0], v[1], v[2]), nil
+		}
+	case "scale":
+		var s float64
+		if json.Unmarshal(args, &s) == nil {
+			return Scaling(s, s, s), nil
+		}
+		var v vec3
+		if err = json.Unmarshal(args, &v); err == nil {
+			return Scaling(v[0], v[1], v[2]), nil
+		}
+	case "rotate_x", "rotate_y", "rotate_z":
+		var radians float64
+		if err = json.Unmarshal(args, &radians); err == nil {
+			switch name {
+			case "rotate_x":
+				return RotationX(radians), nil
+			case "rotate_y":
+				return RotationY(radians), nil
+			default:
+				return RotationZ(radians), nil
+			}
+		}
+	case "shear":
+		var s [6]float64
+		if err = json.Unmarshal(args, &s); err == nil {
+			return Shearing(s[0], s[1], s[2], s[3], s[4], s[5]), nil
+		}
+	case "matrix":
+		var m Matrix
+		if err = json.Unmarshal(args, &m); err == nil {
+			return m, nil
+		}
+	default:
+		return Matrix{}, fmt.Errorf("scene: unknown transform operation %q", name)
+	}
+	return Matrix{}, fmt.Errorf("scene: bad arguments for %q: %w", name, err)
+}
+
+type sceneLoader struct {
+	dir     string
+	objects map[string]Shape
+	// placements holds the center and radius of named spheres, which
+	// animated transforms are applied on top of just as static ones are.
+	placements map[string]Matrix
+	cache      *SceneCache
+	noFiles    bool
+}
+
+func (l sceneLoader) build(doc sceneDocument) (Scene, error) {
+	w := NewWorld()
+
+	for _, o := range doc.Objects {
+		shape, err := l.object(o)
+		if err != nil {
+			return Scene{}, err
+		}
+		if o.Name != "" {
+			if _, exists := l.objects[o.Name]; exists {
+				return Scene{}, fmt.Errorf("scene: duplicate object name %q", o.Name)
+			}
+			l.objects[o.Name] = shape
+			if placement, ok, _ := o.placement(); ok {
+				l.placements[o.Name] = placement
+			}
+		}
+		w.Objects = append(w.Objects, shape)
+	}
+
+	if doc.Light != nil {
+		w.LightSource = NewPointLight(doc.Light.Position.point(), doc.Light.Intensity.color())
+	}
+
+	for _, al := range doc.AreaLights {
+		shape, ok := l.objects[al.Object]
+		if !ok {
+			return Scene{}, fmt.Errorf("scene: area light refers to unknown object %q", al.Object)
+		}
+		sampler, ok := shape.(SurfaceSampler)
+		if !ok {
+			return Scene{}, fmt.Errorf("scene: object %q cannot be used as an area light", al.Object)
+		}
+		w.AreaLights = append(w.AreaLights, NewAreaLight(sampler, al.Samples))
+	}
+
+	if doc.Background != nil {
+		background, err := l.background(*doc.Background)
+		if err != nil {
+			return Scene{}, err
+		}
+		w.Background = background
+	}
+	w.IBLSamples = doc.IBLSamples
+
+	if doc.Fog != nil {
+		fog := NewFog(doc.Fog.Color.color(), doc.Fog.Density)
+		w.Fog = &fog
+	}
+
+	for _, v := range doc.Volumes {
+		boundary, err := l.object(v.Boundary)
+		if err != nil {
+			return Scene{}, err
+		}
+		volume := NewVolume(boundary, v.Absorption, v.Scattering)
+		if v.Color != nil {
+			volume.Color = v.Color.color()
+		}
+		if v.Steps > 0 {
+			volume.Steps = v.Steps
+		}
+		w.Volumes = append(w.Volumes, volume)
+	}
+
+	camera, err := l.camera(doc.Camera)
+	if err != nil {
+		return Scene{}, err
+	}
+
+	scene := Scene{World: w, Camera: camera}
+	if doc.Post != nil {
+		scene.Post = PostProcess{Exposure: doc.Post.Exposure, SRGB: doc.Post.SRGB}
+		if doc.Post.ToneMap != "" {
+			toneMap, err := ParseToneMap(doc.Post.ToneMap)
+			if err != nil {
+				return Scene{}, fmt.Errorf("scene: %w", err)
+			}
+			scene.Post.ToneMap = toneMap
+		}
+	}
+	if doc.Animation != nil {
+		scene.FirstFrame = doc.Animation.Frames[0]
+		scene.LastFrame = doc.Animation.Frames[1]
+		for _, track := range doc.Animation.Tracks {
+			animator, err := l.track(track)
+			if err != nil {
+				return Scene{}, err
+			}
+			scene.Animation = append(scene.Animation, animator)
+		}
+	}
+	return scene, nil
+}
+
+func (l sceneLoader) camera(sc sceneCamera) (Camera, error) {
+	if sc.Width <= 0 || sc.AspectRatio <= 0 || sc.FieldOfView <= 0 {
+		return Camera{}, fmt.Errorf("scene: camera needs a positive width, aspect_ratio and field_of_view")
+	}
+	c := NewCamera(sc.Width, sc.AspectRatio, sc.FieldOfView)
+
+	if sc.From != nil || sc.To != nil || sc.Up != nil {
+		if sc.From == nil || sc.To == nil || sc.Up == nil {
+			return Camera{}, fmt.Errorf("scene: camera from, to and up must be given together")
+		}
+		c.Transform = NewViewTransform(sc.From.point(), sc.To.point(), sc.Up.vector())
+	}
+	if len(sc.Transform) > 0 {
+		m, err := sc.Transform.matrix()
+		if err != nil {
+			return Camera{}, err
+		}
+		if sc.From != nil {
+			m = m.Mul(c.Transform)
+		}
+		c.Transform = m
+	}
+
+	if sc.Samples > 0 {
+		c.SamplesPerPixel = sc.Samples
+	}
+	c.Seed = sc.Seed
+	if sc.Shutter != nil {
+		c.ShutterOpen, c.ShutterClose = sc.Shutter[0], sc.Shutter[1]
+	}
+
+	if sc.Integrator != nil {
+		switch sc.Integrator.Type {
+		case "whitted":
+			integrator := NewWhittedIntegrator()
+			if sc.Integrator.MaxDepth != nil {
+				integrator.MaxDepth = *sc.Integrator.MaxDepth
+			}
+			c.Integrator = integrator
+		case "path":
+			integrator := NewPathTracer()
+			if sc.Integrator.MaxDepth != nil {
+				integrator.MaxDepth = *sc.Integrator.MaxDepth
+			}
+			if sc.Integrator.RouletteDepth != nil {
+				integrator.RouletteDepth = *sc.Integrator.RouletteDepth
+			}
+			c.Integrator = integrator
+		default:
+			return Camera{}, fmt.Errorf("scene: unknown integrator %q", sc.Integrator.Type)
+		}
+	}
+	return c, nil
+}
+
+// placement moves a unit sphere to the object's center and scales it to its
+// radius, after its transform has been applied. It is the identity, and
+// false, for objects that give neither.
+func (o sceneObject) placement() (Matrix, bool, error) {
+	if o.Center == nil && o.Radius == nil {
+		return IdentityMatrix(), false, nil
+	}
+	m := IdentityMatrix()
+	if o.Radius != nil {
+		if *o.Radius <= 0 {
+			return Matrix{}, false, fmt.Errorf("scene: sphere radius must be positive, got %v", *o.Radius)
+		}
+		m = Scaling(*o.Radius, *o.Radius, *o.Radius)
+	}
+	if o.Center != nil {
+		m = Translation(o.Center[0], o.Center[1], o.Center[2]).Mul(m)
+	}
+	return m, true, nil
+}
+
+func (l sceneLoader) object(o sceneObject) (Shape, error) {
+	var shape Shape
+	switch o.Type {
+	case "sphere":
+		s := NewSphere()
+		shape = &s
+	case "plane":
+		p := NewPlane()
+		shape = &p
+	case "rectangle":
+		r := NewRectangle()
+		shape = &r
+	default:
+		return nil, fmt.Errorf("scene: unknown object type %q", o.Type)
+	}
+	if o.Type != "sphere" && (o.Center != nil || o.Radius != nil) {
+		return nil, fmt.Errorf("scene: only spheres take a center and radius")
+	}
+
+	placement, _, err := o.placement()
+	if err != nil {
+		return nil, err
+	}
+	transform, err := o.Transform.matrix()
+	if err != nil {
+		return nil, err
+	}
+	shape.SetTransform(placement.Mul(transform))
+
+	if len(o.EndTransform) > 0 {
+		end, err := o.EndTransform.matrix()
+		if err != nil {
+			return nil, err
+		}
+		end = placement.Mul(end)
+		moving, ok := shape.(MovingShape)
+		if !ok {
+			return nil, fmt.Errorf("scene: %s objects cannot move", o.Type)
+		}
+		moving.SetEndTransform(end)
+	}
+
+	if o.Material != nil {
+		material, err := l.material(*o.Material)
+		if err != nil {
+			return nil, err
+		}
+		shape.SetMaterial(material)
+	}
+	return shape, nil
+}
+
+func (l sceneLoader) material(sm sceneMaterial) (Material, error) {
+	m := NewMaterial()
+	switch sm.Model {
+	case "", "phong":
+	case "microfacet":
+		m.Model = MicrofacetShading
+	default:
+		return Material{}, fmt.Errorf("scene: unknown material model %q", sm.Model)
+	}
+
+	if sm.Color != nil && sm.Pattern != nil {
+		return Material{}, fmt.Errorf("scene: a material takes either a color or a pattern")
+	}
+	if sm.Color != nil {
+		pattern := NewSolidPattern(sm.Color.color())
+		m.Pattern = &pattern
+	}
+	if sm.Pattern != nil {
+		pattern, err := l.pattern(*sm.Pattern)
+		if err != nil {
+			return Material{}, err
+		}
+		m.Pattern = pattern
+	}
+
+	for _, f := range []struct {
+		value *float64
+		field *float64
+	}{
+		{sm.Ambient, &m.Ambient},
+		{sm.Diffuse, &m.Diffuse},
+		{sm.Specular, &m.Specular},
+		{sm.Shininess, &m.Shininess},
+		{sm.Reflective, &m.Reflective},
+		{sm.Transparency, &m.Transparency},
+		{sm.RefractiveIndex, &m.RefractiveIndex},
+		{sm.Metallic, &m.Metallic},
+		{sm.Roughness, &m.Roughness},
+	} {
+		if f.value != nil {
+			*f.field = *f.value
+		}
+	}
+	if sm.GlossySamples != nil {
+		m.GlossySamples = *sm.GlossySamples
+	}
+	if sm.CastsShadow != nil {
+		m.CastsShadow = *sm.CastsShadow
+	}
+	if sm.Emission != nil {
+		m.Emission = sm.Emission.color()
+	}
+	return m, nil
+}
+
+func (l sceneLoader) pattern(sp scenePattern) (Pattern, error) {
+	colors := func() (Color, Color, error) {
+		var a, b vec3
+		if err := json.Unmarshal(sp.A, &a); err != nil {
+			return Color{}, Color{}, fmt.Errorf("scene: %s pattern needs colors a and b: %w", sp.Type, err)
+		}
+		if err := json.Unmarshal(sp.B, &b); err != nil {
+			return Color{}, Color{}, fmt.Errorf("scene: %s pattern needs colors a and b: %w", sp.Type, err)
+		}
+		return a.color(), b.color(), nil
+	}
+
+	var pattern Pattern
+	switch sp.Type {
+	case "solid":
+		if sp.Color == nil {
+			return nil, fmt.Errorf("scene: solid pattern needs a color")
+		}
+		p := NewSolidPattern(sp.Color.color())
+		pattern = &p
+	case "stripe", "gradient", "ring", "checkers":
+		a, b, err := colors()
+		if err != nil {
+			return nil, err
+		}
+		switch sp.Type {
+		case "stripe":
+			p := NewStripePattern(a, b)
+			pattern = &p
+		case "gradient":
+			p := NewGradientPattern(a, b)
+			pattern = &p
+		case "ring":
+			p := NewRingPattern(a, b)
+			pattern = &p
+		default:
+			p := NewCheckersPattern(a, b)
+			pattern = &p
+		}
+	case "blended":
+		var a, b scenePattern
+		if err := json.Unmarshal(sp.A, &a); err != nil {
+			return nil, fmt.Errorf("scene: blended pattern needs patterns a and b: %w", err)
+		}
+		if err := json.Unmarshal(sp.B, &b); err != nil {
+			return nil, fmt.Errorf("scene: blended pattern needs patterns a and b: %w", err)
+		}
+		pa, err := l.pattern(a)
+		if err != nil {
+			return nil, err
+		}
+		pb, err := l.pattern(b)
+		if err != nil {
+			return nil, err
+		}
+		pattern = &BlendedPattern{A: pa, B: pb, Transform: IdentityMatrix()}
+	default:
+		return nil, fmt.Errorf("scene: unknown pattern type %q", sp.Type)
+	}
+
+	transform, err := sp.Transform.matrix()
+	if err != nil {
+		return nil, err
+	}
+	pattern.SetTransform(transform)
+	return pattern, nil
+}
+
+func (l sceneLoader) background(sb sceneBackground) (Background, error) {
+	switch sb.Type {
+	case "constant":
+		if sb.Color == nil {
+			return nil, fmt.Errorf("scene: constant background needs a color")
+		}
+		return NewConstantBackground(sb.Color.color()), nil
+	case "gradient":
+		if sb.Bottom == nil || sb.Top == nil {
+			return nil, fmt.Errorf("scene: gradient background needs bottom and top colors")
+		}
+		return NewGradientBackground(sb.Bottom.color(), sb.Top.color()), nil
+	case "sky":
+		if sb.SunDirection == nil {
+			return nil, fmt.Errorf("scene: sky background needs a sun_direction")
+		}
+		sky := NewSkyBackground(sb.SunDirection.vector())
+		for _, c := range []struct {
+			value *vec3
+			field *Color
+		}{
+			{sb.SunColor, &sky.SunColor},
+			{sb.Zenith, &sky.ZenithColor},
+			{sb.Horizon, &sky.HorizonColor},
+			{sb.Ground, &sky.GroundColor},
+		} {
+			if c.value != nil {
+				*c.field = c.value.color()
+			}
+		}
+		for _, f := range []struct {
+			value *float64
+			field *float64
+		}{
+			{sb.SunAngularRadius, &sky.SunAngularRadius},
+			{sb.GlowIntensity, &sky.GlowIntensity},
+			{sb.GlowConcentration, &sky.GlowConcentration},
+		} {
+			if f.value != nil {
+				*f.field = *f.value
+			}
+		}
+		return sky, nil
+	case "environment":
+		if l.noFiles {
+			return nil, fmt.Errorf("scene: %s: this scene may not refer to files", sb.Path)
+		}
+		path := sb.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(l.dir, path)
+		}
+		image, err := l.cache.loadHDR(path)
+		if err != nil {
+			return nil, fmt.Errorf("scene: %s: %w", sb.Path, err)
+		}
+		env := NewEnvironmentMap(image)
+		env.Path = path
+		if sb.Intensity != nil {
+			env.Intensity = *sb.Intensity
+		}
+		env.Rotation = sb.Rotation
+		return env, nil
+	default:
+		return nil, fmt.Errorf("scene: unknown background type %q", sb.Type)
+	}
+}
+
+func (l sceneLoader) track(st sceneTrack) (Animator, error) {
+	target := strings.Split(st.Target, ".")
+
+	switch {
+	case st.Target == "camera.transform":
+		keys, err := sceneKeys(st.Keys, func(raw json.RawMessage) (Matrix, error) {
+			var t sceneTransform
+			if err := json.Unmarshal(raw, &t); err != nil {
+				return Matrix{}, err
+			}
+			return t.matrix()
+		})
+		return CameraTransformTrack{Track: NewViewTrack(keys...)}, err
+
+	case st.Target == "light.position":
+		keys, err := sceneKeys(st.Keys, func(raw json.RawMessage) (Point, error) {
+			var v vec3
+			err := json.Unmarshal(raw, &v)
+			return v.point(), err
+		})
+		return LightPositionTrack{Track: NewPointTrack(keys...)}, err
+
+	case len(target) >= 3 && target[0] == "objects":
+		shape, ok := l.objects[target[1]]
+		if !ok {
+			return nil, fmt.Errorf("scene: animation targets unknown object %q", target[1])
+		}
+		if len(target) == 3 && target[2] == "transform" {
+			keys, err := sceneKeys(st.Keys, func(raw json.RawMessage) (Matrix, error) {
+				var t sceneTransform
+				if err := json.Unmarshal(raw, &t); err != nil {
+					return Matrix{}, err
+				}
+				m, err := t.matrix()
+				if placement, ok := l.placements[target[1]]; ok {
+					m = placement.Mul(m)
+				}
+				return m, err
+			})
+			return ShapeTransformTrack{Shape: shape, Track: NewMatrixTrack(keys...)}, err
+		}
+		if len(target) == 4 && target[2] == "material" {
+			property, ok := materialProperties[target[3]]
+			if !ok {
+				return nil, fmt.Errorf("scene: cannot animate material property %q", target[3])
+			}
+			keys, err := sceneKeys(st.Keys, func(raw json.RawMessage) (float64, error) {
+				var f float64
+				err := json.Unmarshal(raw, &f)
+				return f, err
+			})
+			return MaterialTrack{Shape: shape, Property: property, Track: NewFloatTrack(keys...)}, err
+		}
+	}
+	return nil, fmt.Errorf("scene: unknown animation target %q", st.Target)
+}
+
+var materialProperties = map[string]MaterialProperty{
+	"ambient":          AmbientProperty,
+	"diffuse":          DiffuseProperty,
+	"specular":         SpecularProperty,
+	"shininess":        ShininessProperty,
+	"reflective":       ReflectiveProperty,
+	"transparency":     TransparencyProperty,
+	"refractive_index": RefractiveIndexProperty,
+	"metallic":         MetallicProperty,
+	"roughness":        RoughnessProperty,
+}
+
+var easings = map[string]Easing{
+	"":            Linear,
+	"linear":      Linear,
+	"ease_in":     EaseIn,
+	"ease_out":    EaseOut,
+	"ease_in_out": EaseInOut,
+}
+
+func sceneKeys[T any](keys []sceneKeyframe, value func(json.RawMessage) (T, error)) ([]Keyframe[T], error) {
+	result := make([]Keyframe[T], len(keys))
+	for i, k := range keys {
+		v, err := value(k.Value)
+		if err != nil {
+			return nil, fmt.Errorf("scene: keyframe %v: %w", k.Frame, err)
+		}
+		easing, ok := easings[k.Easing]
+		if !ok {
+			return nil, fmt.Errorf("scene: unknown easing %q", k.Easing)
+		}
+		result[i] = Keyframe[T]{Frame: k.Frame, Value: v, Easing: easing}
+	}
+	return result, nil
+}
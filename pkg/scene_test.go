@@ -0,0 +1,264 @@
+package goray
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sceneDocumentFixture = `{
+	"camera": {
+		"width": 20,
+		"aspect_ratio": 2,
+		"field_of_view": 1.0471975512,
+		"from": [0, 1.5, -5],
+		"to": [0, 1, 0],
+		"up": [0, 1, 0],
+		"samples": 4,
+		"seed": 9,
+		"shutter": [0, 0.5],
+		"integrator": {"type": "path", "max_depth": 8, "roulette_depth": 2}
+	},
+	"light": {"position": [-10, 10, -10], "intensity": [1, 1, 1]},
+	"area_lights": [{"object": "panel", "samples": 8}],
+	"background": {"type": "gradient", "bottom": [1, 1, 1], "top": [0.5, 0.7, 1]},
+	"ibl_samples": 4,
+	"post": {"exposure": 1.5, "tone_map": "aces", "srgb": true},
+	"fog": {"color": [0.5, 0.5, 0.5], "density": 0.01},
+	"volumes": [
+		{"boundary": {"type": "sphere", "transform": [{"scale": 3}]}, "absorption": 0.1, "scattering": 0.2, "steps": 4}
+	],
+	"objects": [
+		{
+			"name": "floor",
+			"type": "plane",
+			"material": {
+				"pattern": {"type": "checkers", "a": [0, 0, 0], "b": [1, 1, 1], "transform": [{"scale": 0.5}]},
+				"reflective": 0.25,
+				"casts_shadow": false
+			}
+		},
+		{
+			"name": "ball",
+			"type": "sphere",
+			"transform": [{"scale": [0.5, 0.5, 0.5]}, {"translate": [0, 1, 0]}],
+			"end_transform": [{"scale": 0.5}, {"translate": [1, 1, 0]}],
+			"material": {"model": "microfacet", "color": [1, 0, 0.5], "metallic": 1, "roughness": 0.3}
+		},
+		{
+			"name": "panel",
+			"type": "rectangle",
+			"transform": [{"rotate_x": 3.14159265359}, {"translate": [0, 4, 0]}],
+			"material": {"emission": [4, 4, 4], "diffuse": 0}
+		},
+		{
+			"type": "sphere",
+			"material": {
+				"pattern": {
+					"type": "blended",
+					"a": {"type": "stripe", "a": [1, 0, 0], "b": [0, 1, 0]},
+					"b": {"type": "solid", "color": [0, 0, 0.5]}
+				}
+			}
+		}
+	],
+	"animation": {
+		"frames": [1, 24],
+		"tracks": [
+			{"target": "light.position", "keys": [
+				{"frame": 1, "value": [-10, 10, -10]},
+				{"frame": 24, "value": [10, 10, -10], "easing": "ease_in_out"}
+			]},
+			{"target": "objects.ball.transform", "keys": [
+				{"frame": 1, "value": [{"translate": [0, 1, 0]}]},
+				{"frame": 24, "value": [{"translate": [0, 2, 0]}]}
+			]},
+			{"target": "objects.floor.material.reflective", "keys": [
+				{"frame": 1, "value": 0},
+				{"frame": 24, "value": 0.5}
+			]},
+			{"target": "camera.transform", "keys": [
+				{"frame": 1, "value": [{"translate": [0, 0, 5]}]}
+			]}
+		]
+	}
+}`
+
+func TestLoadScene(t *testing.T) {
+	scene, err := LoadScene(strings.NewReader(sceneDocumentFixture))
+	assert.NoError(t, err)
+
+	t.Run("reads the camera", func(t *testing.T) {
+		c := scene.Camera
+		assert.Equal(t, c.Width, 20)
+		assert.Equal(t, c.Height, 10)
+		assert.True(t, MatricesEqual(c.Transform, NewViewTransform(NewPoint(0, 1.5, -5), NewPoint(0, 1, 0), NewVector(0, 1, 0))))
+		assert.Equal(t, c.SamplesPerPixel, 4)
+		assert.Equal(t, c.Seed, uint64(9))
+		assert.Equal(t, c.ShutterClose, 0.5)
+		assert.Equal(t, c.Integrator, PathTracer{MaxDepth: 8, RouletteDepth: 2})
+	})
+
+	t.Run("reads the lights", func(t *testing.T) {
+		w := scene.World
+		assert.Equal(t, w.LightSource, NewPointLight(NewPoint(-10, 10, -10), White()))
+		assert.Len(t, w.AreaLights, 1)
+		assert.Equal(t, w.AreaLights[0].Shape, w.Objects[2])
+		assert.Equal(t, w.AreaLights[0].Samples, 8)
+	})
+
+	t.Run("reads the environment", func(t *testing.T) {
+		w := scene.World
+		assert.Equal(t, w.Background, NewGradientBackground(White(), NewColor(0.5, 0.7, 1)))
+		assert.Equal(t, w.IBLSamples, 4)
+		assert.Equal(t, *w.Fog, NewFog(NewColor(0.5, 0.5, 0.5), 0.01))
+		assert.Len(t, w.Volumes, 1)
+		assert.Equal(t, w.Volumes[0].Steps, 4)
+		assert.True(t, MatricesEqual(w.Volumes[0].Boundary.GetTransform(), Scaling(3, 3, 3)))
+	})
+
+	t.Run("reads the post-processing", func(t *testing.T) {
+		assert.Equal(t, scene.Post, PostProcess{Exposure: 1.5, ToneMap: ACESToneMap, SRGB: true})
+	})
+
+	t.Run("reads the objects", func(t *testing.T) {
+		w := scene.World
+		assert.Len(t, w.Objects, 4)
+
+		floor := w.Objects[0].(*Plane)
+		assert.Equal(t, floor.Material.Reflective, 0.25)
+		assert.False(t, floor.Material.CastsShadow)
+		assert.IsType(t, &CheckersPattern{}, floor.Material.Pattern)
+		assert.True(t, MatricesEqual(floor.Material.Pattern.GetTransform(), Scaling(0.5, 0.5, 0.5)))
+
+		ball := w.Objects[1].(*Sphere)
+		assert.True(t, MatricesEqual(ball.Transform, Translation(0, 1, 0).Mul(Scaling(0.5, 0.5, 0.5))))
+		assert.True(t, MatricesEqual(*ball.EndTransform, Translation(1, 1, 0).Mul(Scaling(0.5, 0.5, 0.5))))
+		assert.Equal(t, ball.Material.Model, MicrofacetShading)
+		assert.Equal(t, ball.Material.Metallic, 1.0)
+		assert.True(t, TuplesEqual(ball.Material.Pattern.At(NewPoint(0, 0, 0)), NewColor(1, 0, 0.5)))
+
+		panel := w.Objects[2].(*Rectangle)
+		assert.Equal(t, panel.Material.Emission, NewColor(4, 4, 4))
+		assert.Equal(t, panel.Material.Diffuse, 0.0)
+
+		blended := w.Objects[3].GetMaterial().Pattern
+		assert.True(t, TuplesEqual(blended.At(NewPoint(0, 0, 0)), NewColor(1, 0, 0.5)))
+	})
+
+	t.Run("reads the animation", func(t *testing.T) {
+		assert.Equal(t, scene.FirstFrame, 1)
+		assert.Equal(t, scene.LastFrame, 24)
+		assert.Len(t, scene.Animation, 4)
+
+		w, c := scene.Frame(24)
+		assert.True(t, TuplesEqual(w.LightSource.Position, NewPoint(10, 10, -10)))
+		assert.True(t, MatricesEqual(w.Objects[1].GetTransform(), Translation(0, 2, 0)))
+		assert.Equal(t, w.Objects[0].GetMaterial().Reflective, 0.5)
+		assert.True(t, MatricesEqual(c.Transform, Translation(0, 0, 5)))
+	})
+}
+
+func TestLoadSceneSphereCenterAndRadius(t *testing.T) {
+	doc := `{
+		"camera": {"width": 10, "aspect_ratio": 1, "field_of_view": 1},
+		"objects": [
+			{"name": "ball", "type": "sphere", "center": [0, 0, 3], "radius": 2},
+			{"type": "sphere", "center": [0, 0, 3], "radius": 2, "transform": [{"scale": [1, 0.5, 1]}]}
+		],
+		"animation": {"frames": [1, 2], "tracks": [
+			{"target": "objects.ball.transform", "keys": [
+				{"frame": 1, "value": []},
+				{"frame": 2, "value": [{"translate": [0, 0, 1]}]}
+			]}
+		]}
+	}`
+	scene, err := LoadScene(strings.NewReader(doc))
+	assert.NoError(t, err)
+
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+	xs := r.Intersect(scene.World.Objects[0])
+	assert.Len(t, xs, 2)
+	assert.InDelta(t, xs[0].T, 6.0, Epsilon)
+	assert.InDelta(t, xs[1].T, 10.0, Epsilon)
+
+	// The transform is applied to the unit sphere before it is placed, so
+	// the flattened sphere is still centred on its center.
+	down := NewRay(NewPoint(0, 5, 3), NewVector(0, -1, 0))
+	xs = down.Intersect(scene.World.Objects[1])
+	assert.Len(t, xs, 2)
+	assert.InDelta(t, xs[0].T, 4.0, Epsilon)
+	assert.InDelta(t, xs[1].T, 6.0, Epsilon)
+
+	w, _ := scene.Frame(2)
+	xs = r.Intersect(w.Objects[0])
+	assert.Len(t, xs, 2)
+	assert.InDelta(t, xs[0].T, 8.0, Epsilon)
+	assert.InDelta(t, xs[1].T, 12.0, Epsilon)
+}
+
+func TestLoadSceneErrors(t *testing.T) {
+	testCases := map[string]string{
+		"invalid json":           `{`,
+		"unknown field":          `{"camera": {"width": 10, "aspect_ratio": 1, "field_of_view": 1}, "objects": [], "bogus": 1}`,
+		"missing camera":         `{"objects": []}`,
+		"unknown object type":    `{"camera": {"width": 10, "aspect_ratio": 1, "field_of_view": 1}, "objects": [{"type": "torus"}]}`,
+		"unknown transform":      `{"camera": {"width": 10, "aspect_ratio": 1, "field_of_view": 1}, "objects": [{"type": "sphere", "transform": [{"twist": 1}]}]}`,
+		"singular transform":     `{"camera": {"width": 10, "aspect_ratio": 1, "field_of_view": 1}, "objects": [{"type": "sphere", "transform": [{"scale": [1, 0, 1]}]}]}`,
+		"non-positive radius":    `{"camera": {"width": 10, "aspect_ratio": 1, "field_of_view": 1}, "objects": [{"type": "sphere", "radius": 0}]}`,
+		"duplicate names":        `{"camera": {"width": 10, "aspect_ratio": 1, "field_of_view": 1}, "objects": [{"name": "a", "type": "sphere"}, {"name": "a", "type": "plane"}]}`,
+		"non-sampled area light": `{"camera": {"width": 10, "aspect_ratio": 1, "field_of_view": 1}, "objects": [{"name": "a", "type": "plane"}], "area_lights": [{"object": "a", "samples": 1}]}`,
+		"unknown track target":   `{"camera": {"width": 10, "aspect_ratio": 1, "field_of_view": 1}, "objects": [], "animation": {"frames": [1, 2], "tracks": [{"target": "objects.nope.transform", "keys": []}]}}`,
+		"unknown tone map":       `{"camera": {"width": 10, "aspect_ratio": 1, "field_of_view": 1}, "objects": [], "post": {"tone_map": "filmic"}}`,
+		"unknown easing":         `{"camera": {"width": 10, "aspect_ratio": 1, "field_of_view": 1}, "objects": [], "animation": {"frames": [1, 2], "tracks": [{"target": "light.position", "keys": [{"frame": 1, "value": [0, 0, 0], "easing": "bounce"}]}]}}`,
+	}
+
+	for description, doc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			_, err := LoadScene(strings.NewReader(doc))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadSceneFileResolvesRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+
+	image := NewCanvas(4, 2)
+	for i := range image.Pixels {
+		image.Pixels[i] = NewColor(0.5, 0.25, 1)
+	}
+	f, err := os.Create(filepath.Join(dir, "sky.hdr"))
+	assert.NoError(t, err)
+	assert.NoError(t, image.WriteHDR(f))
+	f.Close()
+
+	doc := `{
+		"camera": {"width": 10, "aspect_ratio": 1, "field_of_view": 1},
+		"background": {"type": "environment", "path": "sky.hdr", "intensity": 2},
+		"objects": []
+	}`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "scene.json"), []byte(doc), 0o644))
+
+	scene, err := LoadSceneFile(filepath.Join(dir, "scene.json"))
+	assert.NoError(t, err)
+
+	c := scene.World.Background.At(NewVector(0, 1, 0))
+	assert.InDelta(t, c.x, 1.0, 0.01)
+	assert.InDelta(t, c.z, 2.0, 0.01)
+}
+
+func TestSceneTransformOrder(t *testing.T) {
+	doc := `{"camera": {"width": 10, "aspect_ratio": 1, "field_of_view": 1}, "objects": [
+		{"type": "sphere", "transform": [{"rotate_z": 1.5707963268}, {"translate": [1, 0, 0]}]}
+	]}`
+	scene, err := LoadScene(strings.NewReader(doc))
+	assert.NoError(t, err)
+
+	m := scene.World.Objects[0].GetTransform()
+	assert.True(t, MatricesEqual(m, Translation(1, 0, 0).Mul(RotationZ(math.Pi/2))))
+}
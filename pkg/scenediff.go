@@ -0,0 +1,59 @@
+package goray
+
+// SceneDiff describes what changed between two versions of a scene. Objects
+// lists the indices of the objects that changed, in order, when both versions
+// have the same number of objects; World.PrepareChanged keeps what was
+// prepared for the rest. Once objects are added or removed the indices no
+// longer line up, so ObjectsReordered is set instead.
+type SceneDiff struct {
+	Camera           bool
+	Lights           bool
+	Environment      bool
+	Animation        bool
+	Post             bool
+	Objects          []int
+	ObjectsReordered bool
+}
+
+// DiffScenes compares everything that affects the image the two scenes
+// render, the same way HashScene does. Lights and animation tracks that
+// refer to objects only change when they refer to different ones; changes
+// to the objects themselves are reported in Objects.
+func DiffScenes(a, b Scene) SceneDiff {
+	changed := func(part func(s Scene) []any) bool {
+		return hashReferencing(a.World.Objects, part(a)...) != hashReferencing(b.World.Objects, part(b)...)
+	}
+
+	d := SceneDiff{
+		Camera: changed(func(s Scene) []any { return []any{s.Camera} }),
+		Lights: changed(func(s Scene) []any { return []any{s.World.LightSource, s.World.AreaLights} }),
+		Environment: changed(func(s Scene) []any {
+			return []any{s.World.Background, s.World.IBLSamples, s.World.Fog, s.World.Volumes}
+		}),
+		Animation: changed(func(s Scene) []any { return []any{s.Animation, s.FirstFrame, s.LastFrame} }),
+		Post:      a.Post != b.Post,
+	}
+
+	if len(a.World.Objects) != len(b.World.Objects) {
+		d.ObjectsReordered = true
+		return d
+	}
+	for i := range a.World.Objects {
+		if hashOf(a.World.Objects[i]) != hashOf(b.World.Objects[i]) {
+			d.Objects = append(d.Objects, i)
+		}
+	}
+	return d
+}
+
+// Changed reports whether anything at all differs.
+func (d SceneDiff) Changed() bool {
+	return d.NeedsRender() || d.Post
+}
+
+// NeedsRender reports whether the scene has to be traced again. When only
+// the post-processing changed, the previous render can be graded again
+// instead.
+func (d SceneDiff) NeedsRender() bool {
+	return d.Camera || d.Lights || d.Environment || d.Animation || len(d.Objects) > 0 || d.ObjectsReordered
+}
@@ -0,0 +1,108 @@
+package goray
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func loadFixture(t *testing.T, edits ...string) Scene {
+	doc := sceneDocumentFixture
+	for i := 0; i < len(edits); i += 2 {
+		assert.Contains(t, doc, edits[i])
+		doc = strings.Replace(doc, edits[i], edits[i+1], 1)
+	}
+	scene, err := LoadScene(strings.NewReader(doc))
+	assert.NoError(t, err)
+	return scene
+}
+
+func TestDiffScenes(t *testing.T) {
+	base := loadFixture(t)
+
+	testCases := []struct {
+		description string
+		edits       []string
+		expected    SceneDiff
+	}{
+		{"nothing changed", nil, SceneDiff{}},
+		{"the camera moved", []string{`"from": [0, 1.5, -5]`, `"from": [0, 2, -5]`}, SceneDiff{Camera: true}},
+		{"the sample count changed", []string{`"samples": 4`, `"samples": 8`}, SceneDiff{Camera: true}},
+		{"the light moved", []string{`"light": {"position": [-10, 10, -10]`, `"light": {"position": [-10, 10, -9]`}, SceneDiff{Lights: true}},
+		{"the area light's samples changed", []string{`"samples": 8}`, `"samples": 16}`}, SceneDiff{Lights: true}},
+		{"the fog thickened", []string{`"density": 0.01`, `"density": 0.02`}, SceneDiff{Environment: true}},
+		{"the grading changed", []string{`"exposure": 1.5`, `"exposure": 1`}, SceneDiff{Post: true}},
+		{"one material changed", []string{`"reflective": 0.25`, `"reflective": 0.5`}, SceneDiff{Objects: []int{0}}},
+		{"one object moved", []string{`"end_transform": [{"scale": 0.5}, {"translate": [1, 1, 0]}]`, `"end_transform": [{"scale": 0.5}, {"translate": [2, 1, 0]}]`}, SceneDiff{Objects: []int{1}}},
+		{"the animation changed", []string{`"frames": [1, 24]`, `"frames": [1, 48]`}, SceneDiff{Animation: true}},
+		{"an object changed type", []string{`{
+			"name": "floor",
+			"type": "plane",`, `{
+			"name": "floor",
+			"type": "sphere",`}, SceneDiff{Objects: []int{0}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, DiffScenes(base, loadFixture(t, tc.edits...)), tc.expected)
+		})
+	}
+
+	t.Run("objects added or removed", func(t *testing.T) {
+		fewer := loadFixture(t)
+		fewer.World.Objects = fewer.World.Objects[:3]
+		assert.Equal(t, DiffScenes(base, fewer), SceneDiff{ObjectsReordered: true})
+	})
+}
+
+func TestSceneDiffPredicates(t *testing.T) {
+	assert.False(t, SceneDiff{}.Changed())
+	assert.False(t, SceneDiff{}.NeedsRender())
+
+	assert.True(t, SceneDiff{Post: true}.Changed())
+	assert.False(t, SceneDiff{Post: true}.NeedsRender())
+
+	assert.True(t, SceneDiff{Objects: []int{2}}.NeedsRender())
+	assert.True(t, SceneDiff{ObjectsReordered: true}.NeedsRender())
+	assert.True(t, SceneDiff{Camera: true}.NeedsRender())
+}
+
+func TestSceneCacheReusesEnvironmentMaps(t *testing.T) {
+	dir := t.TempDir()
+	hdrPath := filepath.Join(dir, "sky.hdr")
+	writeHDR := func(c Color) {
+		image := blankCanvas(2, 1)
+		image.Write(0, 0, c)
+		image.Write(1, 0, c)
+		f, err := os.Create(hdrPath)
+		assert.NoError(t, err)
+		assert.NoError(t, image.WriteHDR(f))
+		assert.NoError(t, f.Close())
+	}
+	writeHDR(NewColor(1, 1, 1))
+
+	scenePath := filepath.Join(dir, "scene.json")
+	doc := `{"camera": {"width": 4, "aspect_ratio": 1, "field_of_view": 1}, "background": {"type": "environment", "path": "sky.hdr"}, "objects": []}`
+	assert.NoError(t, os.WriteFile(scenePath, []byte(doc), 0o644))
+
+	cache := NewSceneCache()
+	first, err := cache.LoadSceneFile(scenePath)
+	assert.NoError(t, err)
+	second, err := cache.LoadSceneFile(scenePath)
+	assert.NoError(t, err)
+
+	firstPixels := first.World.Background.(EnvironmentMap).Image.Pixels
+	secondPixels := second.World.Background.(EnvironmentMap).Image.Pixels
+	assert.Same(t, &firstPixels[0], &secondPixels[0])
+	assert.False(t, DiffScenes(first, second).Changed())
+
+	writeHDR(NewColor(0.5, 0.5, 0.5))
+	os.Chtimes(hdrPath, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	third, err := cache.LoadSceneFile(scenePath)
+	assert.NoError(t, err)
+	assert.True(t, DiffScenes(second, third).Environment)
+}
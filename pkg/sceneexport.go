@@ -0,0 +1,461 @@
+package goray
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// SaveScene writes s as a JSON scene document that LoadScene reads back into
+// the same scene. Objects that area lights or animation tracks refer to are
+// named object_N after their index in the world. Shapes, patterns,
+// backgrounds, integrators and animators the format has no type for are an
+// error, as is an environment map that was not loaded from a file.
+func SaveScene(w io.Writer, s Scene) error {
+	return saveScene(w, s, "")
+}
+
+// SaveSceneFile writes s to path. Environment maps are written relative to
+// the directory path is in, the same way LoadSceneFile resolves them.
+func SaveSceneFile(path string, s Scene) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := saveScene(f, s, filepath.Dir(path)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func saveScene(w io.Writer, s Scene, dir string) error {
+	saver := sceneSaver{dir: dir, indices: map[Shape]int{}}
+	doc, err := saver.document(s)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+func toVec3[T Point | Vector | Color](t T) vec3 {
+	u := Tuple(t)
+	return vec3{u.x, u.y, u.z}
+}
+
+func toVec3Ptr[T Point | Vector | Color](t T) *vec3 {
+	v := toVec3(t)
+	return &v
+}
+
+// nameOf finds the name a lookup table such as easings gives to v.
+func nameOf[V comparable](names map[string]V, v V) (string, bool) {
+	for name, value := range names {
+		if name != "" && value == v {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func rawJSON(v any) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	return json.RawMessage(data), err
+}
+
+// matrixTransform writes m as a single matrix operation, or as no operations
+// at all when it is the identity and omitIdentity is set.
+func matrixTransform(m Matrix, omitIdentity bool) (sceneTransform, error) {
+	if omitIdentity && m == IdentityMatrix() {
+		return nil, nil
+	}
+	args, err := rawJSON(m)
+	if err != nil {
+		return nil, fmt.Errorf("scene: %w", err)
+	}
+	return sceneTransform{{"matrix": args}}, nil
+}
+
+type sceneSaver struct {
+	dir     string
+	objects []sceneObject
+	indices map[Shape]int
+}
+
+func (sv *sceneSaver) document(s Scene) (sceneDocument, error) {
+	w := s.World
+	doc := sceneDocument{
+		IBLSamples: w.IBLSamples,
+		Objects:    make([]sceneObject, len(w.Objects)),
+	}
+	if w.LightSource != (PointLight{}) {
+		doc.Light = &sceneLight{Position: toVec3(w.LightSource.Position), Intensity: toVec3(w.LightSource.Intensity)}
+	}
+
+	for i, shape := range w.Objects {
+		o, err := sv.object(shape)
+		if err != nil {
+			return sceneDocument{}, err
+		}
+		doc.Objects[i] = o
+		sv.indices[shape] = i
+	}
+	sv.objects = doc.Objects
+
+	camera, err := sv.camera(s.Camera)
+	if err != nil {
+		return sceneDocument{}, err
+	}
+	doc.Camera = camera
+
+	for _, al := range w.AreaLights {
+		name, err := sv.name(al.Shape)
+		if err != nil {
+			return sceneDocument{}, err
+		}
+		doc.AreaLights = append(doc.AreaLights, sceneAreaLight{Object: name, Samples: al.Samples})
+	}
+
+	if w.Background != nil {
+		background, err := sv.background(w.Background)
+		if err != nil {
+			return sceneDocument{}, err
+		}
+		doc.Background = background
+	}
+
+	if w.Fog != nil {
+		doc.Fog = &sceneFog{Color: toVec3(w.Fog.Color), Density: w.Fog.Density}
+	}
+
+	for _, v := range w.Volumes {
+		boundary, err := sv.object(v.Boundary)
+		if err != nil {
+			return sceneDocument{}, err
+		}
+		doc.Volumes = append(doc.Volumes, sceneVolume{
+			Boundary:   boundary,
+			Absorption: v.Absorption,
+			Scattering: v.Scattering,
+			Color:      toVec3Ptr(v.Color),
+			Steps:      v.Steps,
+		})
+	}
+
+	if s.Post != (PostProcess{}) {
+		doc.Post = &scenePost{Exposure: s.Post.Exposure, SRGB: s.Post.SRGB}
+		if s.Post.ToneMap != ClampToneMap {
+			name, ok := nameOf(toneMaps, s.Post.ToneMap)
+			if !ok {
+				return sceneDocument{}, fmt.Errorf("scene: cannot save tone map %d", s.Post.ToneMap)
+			}
+			doc.Post.ToneMap = name
+		}
+	}
+
+	if len(s.Animation) > 0 || s.FirstFrame != 0 || s.LastFrame != 0 {
+		doc.Animation = &sceneAnimation{Frames: [2]int{s.FirstFrame, s.LastFrame}, Tracks: []sceneTrack{}}
+		for _, animator := range s.Animation {
+			track, err := sv.track(animator)
+			if err != nil {
+				return sceneDocument{}, err
+			}
+			doc.Animation.Tracks = append(doc.Animation.Tracks, track)
+		}
+	}
+	return doc, nil
+}
+
+// name returns the name of one of the world's objects, giving it one if it
+// does not have one yet.
+func (sv *sceneSaver) name(shape Shape) (string, error) {
+	i, ok := sv.indices[shape]
+	if !ok {
+		return "", fmt.Errorf("scene: cannot save a reference to a %T that is not one of the world's objects", shape)
+	}
+	if sv.objects[i].Name == "" {
+		sv.objects[i].Name = fmt.Sprintf("object_%d", i)
+	}
+	return sv.objects[i].Name, nil
+}
+
+func (sv *sceneSaver) camera(c Camera) (sceneCamera, error) {
+	transform, err := matrixTransform(c.Transform, true)
+	if err != nil {
+		return sceneCamera{}, err
+	}
+	sc := sceneCamera{
+		Width:       c.Width,
+		AspectRatio: c.AspectRatio,
+		FieldOfView: c.FieldOfView,
+		Transform:   transform,
+		Samples:     c.SamplesPerPixel,
+		Seed:        c.Seed,
+	}
+	if c.ShutterOpen != 0 || c.ShutterClose != 0 {
+		sc.Shutter = &[2]float64{c.ShutterOpen, c.ShutterClose}
+	}
+
+	switch integrator := c.Integrator.(type) {
+	case nil:
+	case WhittedIntegrator:
+		sc.Integrator = &sceneIntegrator{Type: "whitted", MaxDepth: &integrator.MaxDepth}
+	case PathTracer:
+		sc.Integrator = &sceneIntegrator{Type: "path", MaxDepth: &integrator.MaxDepth, RouletteDepth: &integrator.RouletteDepth}
+	default:
+		return sceneCamera{}, fmt.Errorf("scene: cannot save a %T integrator", c.Integrator)
+	}
+	return sc, nil
+}
+
+func (sv *sceneSaver) object(shape Shape) (sceneObject, error) {
+	var o sceneObject
+	switch shape.(type) {
+	case *Sphere:
+		// A sphere's Center and Radius play no part in intersecting it, so
+		// its transform alone says where it is and how big.
+		o.Type = "sphere"
+	case *Plane:
+		o.Type = "plane"
+	case *Rectangle:
+		o.Type = "rectangle"
+	default:
+		return sceneObject{}, fmt.Errorf("scene: cannot save %T objects", shape)
+	}
+
+	transform, err := matrixTransform(shape.GetTransform(), true)
+	if err != nil {
+		return sceneObject{}, err
+	}
+	o.Transform = transform
+
+	if moving, ok := shape.(MovingShape); ok && moving.GetEndTransform() != nil {
+		end, err := matrixTransform(*moving.GetEndTransform(), false)
+		if err != nil {
+			return sceneObject{}, err
+		}
+		o.EndTransform = end
+	}
+
+	material, err := sv.material(shape.GetMaterial())
+	if err != nil {
+		return sceneObject{}, err
+	}
+	o.Material = &material
+	return o, nil
+}
+
+// material writes every property, so the document does not depend on the
+// defaults NewMaterial happens to have.
+func (sv *sceneSaver) material(m Material) (sceneMaterial, error) {
+	sm := sceneMaterial{
+		Ambient:         &m.Ambient,
+		Diffuse:         &m.Diffuse,
+		Specular:        &m.Specular,
+		Shininess:       &m.Shininess,
+		Reflective:      &m.Reflective,
+		Transparency:    &m.Transparency,
+		RefractiveIndex: &m.RefractiveIndex,
+		Metallic:        &m.Metallic,
+		Roughness:       &m.Roughness,
+		GlossySamples:   &m.GlossySamples,
+		CastsShadow:     &m.CastsShadow,
+		Emission:        toVec3Ptr(m.Emission),
+	}
+	switch m.Model {
+	case PhongShading:
+		sm.Model = "phong"
+	case MicrofacetShading:
+		sm.Model = "microfacet"
+	default:
+		return sceneMaterial{}, fmt.Errorf("scene: cannot save shading model %d", m.Model)
+	}
+
+	if m.Pattern != nil {
+		pattern, err := sv.pattern(m.Pattern)
+		if err != nil {
+			return sceneMaterial{}, err
+		}
+		sm.Pattern = &pattern
+	}
+	return sm, nil
+}
+
+func (sv *sceneSaver) pattern(p Pattern) (scenePattern, error) {
+	var sp scenePattern
+	colors := func(typ string, a, b Color) error {
+		var err error
+		sp.Type = typ
+		if sp.A, err = rawJSON(toVec3(a)); err != nil {
+			return fmt.Errorf("scene: %w", err)
+		}
+		if sp.B, err = rawJSON(toVec3(b)); err != nil {
+			return fmt.Errorf("scene: %w", err)
+		}
+		return nil
+	}
+
+	var err error
+	switch pattern := p.(type) {
+	case *SolidPattern:
+		sp.Type = "solid"
+		sp.Color = toVec3Ptr(pattern.Color)
+	case *StripePattern:
+		err = colors("stripe", pattern.A, pattern.B)
+	case *GradientPattern:
+		err = colors("gradient", pattern.A, pattern.B)
+	case *RingPattern:
+		err = colors("ring", pattern.A, pattern.B)
+	case *CheckersPattern:
+		err = colors("checkers", pattern.A, pattern.B)
+	case *BlendedPattern:
+		sp.Type = "blended"
+		var a, b scenePattern
+		if a, err = sv.pattern(pattern.A); err != nil {
+			return scenePattern{}, err
+		}
+		if b, err = sv.pattern(pattern.B); err != nil {
+			return scenePattern{}, err
+		}
+		if sp.A, err = rawJSON(a); err != nil {
+			return scenePattern{}, fmt.Errorf("scene: %w", err)
+		}
+		if sp.B, err = rawJSON(b); err != nil {
+			return scenePattern{}, fmt.Errorf("scene: %w", err)
+		}
+	default:
+		return scenePattern{}, fmt.Errorf("scene: cannot save %T patterns", p)
+	}
+	if err != nil {
+		return scenePattern{}, err
+	}
+
+	sp.Transform, err = matrixTransform(p.GetTransform(), true)
+	return sp, err
+}
+
+func (sv *sceneSaver) background(b Background) (*sceneBackground, error) {
+	switch background := b.(type) {
+	case ConstantBackground:
+		return &sceneBackground{Type: "constant", Color: toVec3Ptr(background.Color)}, nil
+	case GradientBackground:
+		return &sceneBackground{Type: "gradient", Bottom: toVec3Ptr(background.Bottom), Top: toVec3Ptr(background.Top)}, nil
+	case SkyBackground:
+		return &sceneBackground{
+			Type:              "sky",
+			SunDirection:      toVec3Ptr(background.SunDirection),
+			SunColor:          toVec3Ptr(background.SunColor),
+			SunAngularRadius:  &background.SunAngularRadius,
+			Zenith:            toVec3Ptr(background.ZenithColor),
+			Horizon:           toVec3Ptr(background.HorizonColor),
+			Ground:            toVec3Ptr(background.GroundColor),
+			GlowIntensity:     &background.GlowIntensity,
+			GlowConcentration: &background.GlowConcentration,
+		}, nil
+	case EnvironmentMap:
+		if background.Path == "" {
+			return nil, fmt.Errorf("scene: cannot save an environment map that was not loaded from a file")
+		}
+		path, err := sv.relativePath(background.Path)
+		if err != nil {
+			return nil, err
+		}
+		return &sceneBackground{
+			Type:      "environment",
+			Path:      path,
+			Intensity: &background.Intensity,
+			Rotation:  background.Rotation,
+		}, nil
+	default:
+		return nil, fmt.Errorf("scene: cannot save a %T background", b)
+	}
+}
+
+// relativePath makes path relative to the directory the document is saved
+// in. Without one, path is written as it is.
+func (sv *sceneSaver) relativePath(path string) (string, error) {
+	if sv.dir == "" {
+		return path, nil
+	}
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	dir, err := filepath.Abs(sv.dir)
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(dir, target); err == nil {
+		return rel, nil
+	}
+	return target, nil
+}
+
+func (sv *sceneSaver) track(animator Animator) (sceneTrack, error) {
+	matrixValue := func(m Matrix) (any, error) {
+		return matrixTransform(m, false)
+	}
+
+	var st sceneTrack
+	var err error
+	switch track := animator.(type) {
+	case CameraTransformTrack:
+		st.Target = "camera.transform"
+		st.Keys, err = saveKeys(track.Track.Keys, matrixValue)
+	case LightPositionTrack:
+		st.Target = "light.position"
+		st.Keys, err = saveKeys(track.Track.Keys, func(p Point) (any, error) {
+			return toVec3(p), nil
+		})
+	case ShapeTransformTrack:
+		var name string
+		if name, err = sv.name(track.Shape); err != nil {
+			return sceneTrack{}, err
+		}
+		st.Target = "objects." + name + ".transform"
+		st.Keys, err = saveKeys(track.Track.Keys, matrixValue)
+	case MaterialTrack:
+		var name string
+		if name, err = sv.name(track.Shape); err != nil {
+			return sceneTrack{}, err
+		}
+		property, ok := nameOf(materialProperties, track.Property)
+		if !ok {
+			return sceneTrack{}, fmt.Errorf("scene: cannot save material property %d", track.Property)
+		}
+		st.Target = "objects." + name + ".material." + property
+		st.Keys, err = saveKeys(track.Track.Keys, func(f float64) (any, error) {
+			return f, nil
+		})
+	default:
+		return sceneTrack{}, fmt.Errorf("scene: cannot save a %T animator", animator)
+	}
+	return st, err
+}
+
+func saveKeys[T any](keys []Keyframe[T], value func(T) (any, error)) ([]sceneKeyframe, error) {
+	result := make([]sceneKeyframe, len(keys))
+	for i, k := range keys {
+		v, err := value(k.Value)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := rawJSON(v)
+		if err != nil {
+			return nil, fmt.Errorf("scene: keyframe %v: %w", k.Frame, err)
+		}
+		result[i] = sceneKeyframe{Frame: k.Frame, Value: raw}
+		if k.Easing != Linear {
+			easing, ok := nameOf(easings, k.Easing)
+			if !ok {
+				return nil, fmt.Errorf("scene: cannot save easing %d", k.Easing)
+			}
+			result[i].Easing = easing
+		}
+	}
+	return result, nil
+}
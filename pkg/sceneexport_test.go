@@ -0,0 +1,177 @@
+package goray
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func roundTrip(t *testing.T, s Scene) Scene {
+	var buf bytes.Buffer
+	assert.NoError(t, SaveScene(&buf, s))
+	loaded, err := LoadScene(&buf)
+	assert.NoError(t, err)
+	return loaded
+}
+
+func TestSaveSceneRoundTripsALoadedScene(t *testing.T) {
+	scene := loadFixture(t)
+	loaded := roundTrip(t, scene)
+
+	assert.Equal(t, DiffScenes(scene, loaded), SceneDiff{})
+	assert.Equal(t, HashScene(loaded.World, loaded.Camera), HashScene(scene.World, scene.Camera))
+
+	w, c := loaded.Frame(24)
+	assert.True(t, TuplesEqual(w.LightSource.Position, NewPoint(10, 10, -10)))
+	assert.Equal(t, w.Objects[0].GetMaterial().Reflective, 0.5)
+	assert.True(t, MatricesEqual(c.Transform, Translation(0, 0, 5)))
+}
+
+func TestSaveSceneRoundTripsABuiltScene(t *testing.T) {
+	ball := NewSphere()
+	ball.SetTransform(Translation(1, 2, 3).Mul(RotationY(math.Pi / 3)).Mul(Scaling(0.5, 1, 0.5)))
+	ball.SetEndTransform(IdentityMatrix())
+	ball.Material = NewPBRMaterial(NewColor(0.9, 0.1, 0.1), 0.5, 0.2, 1.5)
+
+	stripes := NewStripePattern(Black(), White())
+	stripes.SetTransform(RotationZ(-0.5))
+	rings := NewRingPattern(NewColor(1, 0, 0), NewColor(0, 0, 1))
+	floor := NewPlane()
+	floor.Material.Pattern = &BlendedPattern{A: &stripes, B: &rings, Transform: Scaling(2, 2, 2)}
+	floor.Material.GlossySamples = 4
+
+	panel := NewRectangle()
+	panel.SetTransform(Translation(0, 5, 0))
+	panel.Material.Emission = NewColor(3, 3, 3)
+
+	w := NewWorld()
+	w.LightSource = NewPointLight(NewPoint(-1, 4, -2), NewColor(0.5, 0.5, 0.5))
+	w.Objects = []Shape{&ball, &floor, &panel}
+	w.AreaLights = []AreaLight{NewAreaLight(&panel, 4)}
+	sky := NewSkyBackground(NewVector(0, 1, 0))
+	sky.GlowIntensity = 0.25
+	w.Background = sky
+	boundary := NewSphere()
+	w.Volumes = []Volume{NewVolume(&boundary, 0.1, 0.3)}
+
+	c := NewCamera(40, 1.5, math.Pi/4)
+	c.Transform = NewViewTransform(NewPoint(0, 1, -6), NewPoint(0, 1, 0), NewVector(0, 1, 0))
+	c.Integrator = WhittedIntegrator{MaxDepth: 0}
+	c.SamplesPerPixel = 2
+
+	scene := Scene{
+		World:  w,
+		Camera: c,
+		Animation: Animation{
+			MaterialTrack{Shape: &floor, Property: RoughnessProperty, Track: NewFloatTrack(
+				Keyframe[float64]{Frame: 0, Value: 0.1},
+				Keyframe[float64]{Frame: 10, Value: 0.9, Easing: EaseOut},
+			)},
+			ShapeTransformTrack{Shape: &ball, Track: NewMatrixTrack(
+				Keyframe[Matrix]{Frame: 0, Value: RotationX(0)},
+			)},
+		},
+		LastFrame: 10,
+		Post:      PostProcess{Exposure: -1, ToneMap: ReinhardToneMap},
+	}
+	loaded := roundTrip(t, scene)
+
+	assert.Equal(t, DiffScenes(scene, loaded), SceneDiff{})
+	assert.Equal(t, loaded.World.Objects[0].GetTransform(), ball.Transform)
+	assert.Equal(t, loaded.World.AreaLights[0].Shape, loaded.World.Objects[2])
+	assert.Equal(t, loaded.Camera.Integrator, WhittedIntegrator{MaxDepth: 0})
+}
+
+func TestSaveSceneFileWritesRelativeEnvironmentPaths(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "sky.hdr"))
+	assert.NoError(t, err)
+	assert.NoError(t, NewCanvas(4, 2).WriteHDR(f))
+	f.Close()
+
+	doc := `{
+		"camera": {"width": 10, "aspect_ratio": 1, "field_of_view": 1},
+		"background": {"type": "environment", "path": "sky.hdr", "rotation": 1},
+		"objects": []
+	}`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "scene.json"), []byte(doc), 0o644))
+	scene, err := LoadSceneFile(filepath.Join(dir, "scene.json"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "copies"), 0o755))
+	path := filepath.Join(dir, "copies", "scene.json")
+	assert.NoError(t, SaveSceneFile(path, scene))
+
+	saved, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var written sceneDocument
+	assert.NoError(t, json.Unmarshal(saved, &written))
+	assert.Equal(t, written.Background.Path, filepath.Join("..", "sky.hdr"))
+
+	loaded, err := LoadSceneFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, DiffScenes(scene, loaded), SceneDiff{})
+}
+
+type unsavableShape struct {
+	Plane
+}
+
+type unsavablePattern struct {
+	SolidPattern
+}
+
+func TestSaveSceneErrors(t *testing.T) {
+	valid := func() Scene {
+		return Scene{World: NewWorld(), Camera: NewCamera(10, 1, 1)}
+	}
+	stray := NewRectangle()
+
+	testCases := map[string]func(s *Scene){
+		"unknown shape": func(s *Scene) {
+			s.World.Objects = []Shape{&unsavableShape{NewPlane()}}
+		},
+		"unknown pattern": func(s *Scene) {
+			sphere := NewSphere()
+			sphere.Material.Pattern = &unsavablePattern{NewSolidPattern(White())}
+			s.World.Objects = []Shape{&sphere}
+		},
+		"environment map without a file": func(s *Scene) {
+			s.World.Background = NewEnvironmentMap(NewCanvas(4, 2))
+		},
+		"area light outside the world": func(s *Scene) {
+			s.World.AreaLights = []AreaLight{NewAreaLight(&stray, 1)}
+		},
+	}
+
+	for description, edit := range testCases {
+		t.Run(description, func(t *testing.T) {
+			s := valid()
+			edit(&s)
+			var buf bytes.Buffer
+			assert.Error(t, SaveScene(&buf, s))
+		})
+	}
+}
+
+func TestSaveSceneOnlyNamesReferencedObjects(t *testing.T) {
+	scene := loadFixture(t)
+	var buf bytes.Buffer
+	assert.NoError(t, SaveScene(&buf, scene))
+
+	var doc sceneDocument
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	names := []string{}
+	for _, o := range doc.Objects {
+		names = append(names, o.Name)
+	}
+	assert.Equal(t, names, []string{"object_0", "object_1", "object_2", ""})
+	assert.Equal(t, doc.AreaLights[0].Object, "object_2")
+	assert.True(t, strings.HasPrefix(doc.Animation.Tracks[1].Target, "objects.object_1."))
+}
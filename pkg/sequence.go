@@ -0,0 +1,104 @@
+package goray
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+)
+
+// Frame returns the world and camera as they are at frame, with the scene's
+// animation applied. Animated shapes are copied first, so the scene itself is
+// left as it was and frames can be rendered in any order.
+func (s Scene) Frame(frame int) (World, Camera) {
+	w, c := s.World, s.Camera
+	s.animationOnCopies(&w).Apply(float64(frame), &w, &c)
+	return w, c
+}
+
+// shapeAnimator is an Animator that changes a shape in place.
+type shapeAnimator interface {
+	Animator
+	target() Shape
+	retarget(shape Shape) Animator
+}
+
+// animationOnCopies replaces every shape the animation changes with a copy,
+// in w's objects and area lights, and returns the animation retargeted at
+// the copies.
+func (s Scene) animationOnCopies(w *World) Animation {
+	copies := map[Shape]Shape{}
+	animation := slices.Clone(s.Animation)
+	for i, animator := range animation {
+		sa, ok := animator.(shapeAnimator)
+		if !ok {
+			continue
+		}
+		shape := sa.target()
+		if _, ok := copies[shape]; !ok {
+			copies[shape] = copyShape(shape)
+		}
+		animation[i] = sa.retarget(copies[shape])
+	}
+	if len(copies) == 0 {
+		return animation
+	}
+
+	w.Objects = slices.Clone(w.Objects)
+	for i, object := range w.Objects {
+		if shape, ok := copies[object]; ok {
+			w.Objects[i] = shape
+		}
+	}
+	w.AreaLights = slices.Clone(w.AreaLights)
+	for i, light := range w.AreaLights {
+		if shape, ok := copies[light.Shape]; ok {
+			w.AreaLights[i].Shape = shape.(SurfaceSampler)
+		}
+	}
+	return animation
+}
+
+// copyShape makes a shallow copy of the struct shape points to.
+func copyShape(shape Shape) Shape {
+	v := reflect.ValueOf(shape)
+	if v.Kind() != reflect.Pointer {
+		return shape
+	}
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	return c.Interface().(Shape)
+}
+
+func (s Scene) RenderFrame(frame int) Canvas {
+	w, c := s.Frame(frame)
+	return c.Render(w).PostProcess(s.Post)
+}
+
+// RenderSequence renders every frame from first to last inclusive and writes
+// them into dir as frame_0001.png, frame_0002.png and so on.
+func (s Scene) RenderSequence(first, last int, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for frame := first; frame <= last; frame++ {
+		canvas := s.RenderFrame(frame)
+		if err := writeFrame(canvas, filepath.Join(dir, fmt.Sprintf("frame_%04d.png", frame))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFrame(c Canvas, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := c.WritePNG(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
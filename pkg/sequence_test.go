@@ -0,0 +1,79 @@
+package goray
+
+import (
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderSequence(t *testing.T) {
+	w := defaultWorld()
+	c := NewCamera(5, 1, math.Pi/2)
+	c.Transform = NewViewTransform(NewPoint(0, 0, -5), NewPoint(0, 0, 0), NewVector(0, 1, 0))
+
+	scene := Scene{
+		World:  w,
+		Camera: c,
+		Animation: Animation{
+			LightPositionTrack{Track: NewPointTrack(
+				Keyframe[Point]{Frame: 1, Value: NewPoint(-10, 10, -10)},
+				Keyframe[Point]{Frame: 3, Value: NewPoint(0, -10, -10)},
+			)},
+		},
+	}
+
+	dir := t.TempDir()
+	err := scene.RenderSequence(1, 3, dir)
+	assert.NoError(t, err)
+
+	for _, name := range []string{"frame_0001.png", "frame_0002.png", "frame_0003.png"} {
+		f, err := os.Open(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		img, err := png.Decode(f)
+		f.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, img.Bounds().Dx(), 5)
+	}
+
+	first := scene.RenderFrame(1)
+	last := scene.RenderFrame(3)
+	assert.NotEqual(t, first.At(2, 2), last.At(2, 2))
+}
+
+func TestFrameLeavesTheSceneAsItWas(t *testing.T) {
+	w := defaultWorld()
+	panel := NewRectangle()
+	w.Objects = append(w.Objects, &panel)
+	w.AreaLights = []AreaLight{NewAreaLight(&panel, 1)}
+	original := w.Objects[0].GetTransform()
+
+	scene := Scene{
+		World:  w,
+		Camera: NewCamera(5, 1, math.Pi/2),
+		Animation: Animation{
+			ShapeTransformTrack{Shape: w.Objects[0], Track: NewMatrixTrack(
+				Keyframe[Matrix]{Frame: 1, Value: Translation(0, 1, 0)},
+				Keyframe[Matrix]{Frame: 3, Value: Translation(0, 3, 0)},
+			)},
+			MaterialTrack{Shape: &panel, Property: DiffuseProperty, Track: NewFloatTrack(
+				Keyframe[float64]{Frame: 1, Value: 0.25},
+				Keyframe[float64]{Frame: 3, Value: 0.75},
+			)},
+		},
+	}
+
+	later, _ := scene.Frame(3)
+	earlier, _ := scene.Frame(1)
+	assert.Equal(t, later.Objects[0].GetTransform(), Translation(0, 3, 0))
+	assert.Equal(t, earlier.Objects[0].GetTransform(), Translation(0, 1, 0))
+	assert.Equal(t, later.AreaLights[0].Shape.GetMaterial().Diffuse, 0.75)
+	assert.Same(t, later.AreaLights[0].Shape, later.Objects[2])
+
+	assert.Equal(t, scene.World.Objects[0].GetTransform(), original)
+	assert.Equal(t, panel.Material.Diffuse, NewMaterial().Diffuse)
+	assert.Same(t, scene.World.Objects[1], later.Objects[1])
+}
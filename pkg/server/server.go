@@ -0,0 +1,300 @@
+// Package server runs renders for HTTP clients. Scenes are submitted as
+// JSON documents, queued, rendered a bounded number at a time and fetched
+// back as images once they are done. Documents may not refer to files, and
+// must keep within the server's limits on image size, sample counts and
+// recursion depth.
+// Finished jobs are forgotten once they are older than the server's TTL.
+//
+//	POST   /jobs?frame=N       submit a scene document, returns the job
+//	GET    /jobs               list every job
+//	GET    /jobs/{id}          a job's status and progress
+//	DELETE /jobs/{id}          cancel a queued or running job
+//	GET    /jobs/{id}/image    the finished image, ?format=png (default) or ppm
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	g "github.com/mikowitz/goray/pkg"
+)
+
+type Status string
+
+const (
+	Queued   Status = "queued"
+	Running  Status = "running"
+	Done     Status = "done"
+	Failed   Status = "failed"
+	Canceled Status = "canceled"
+)
+
+// maxSceneSize caps how much of a request body is read as a scene document.
+const maxSceneSize = 32 << 20
+
+type Job struct {
+	ID       string    `json:"id"`
+	Status   Status    `json:"status"`
+	Progress float64   `json:"progress"`
+	Error    string    `json:"error,omitempty"`
+	Created  time.Time `json:"created"`
+
+	image    g.Canvas
+	cancel   context.CancelFunc
+	finished time.Time
+}
+
+// Server renders submitted scenes, refusing any that ask for more than its
+// Limits. At most MaxPending jobs may be queued or running at once; more are
+// turned away until some finish. TTL is how long a finished job, and its
+// image, are kept for.
+type Server struct {
+	g.Limits
+	MaxPending int
+	TTL        time.Duration
+
+	mu     sync.Mutex
+	jobs   map[string]*Job
+	slots  chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mux    *http.ServeMux
+}
+
+// NewServer returns a server that renders at most concurrency jobs at once.
+// Jobs submitted beyond that wait in the queue for a free slot.
+func NewServer(concurrency int) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		Limits:     g.NewLimits(),
+		MaxPending: 64,
+		TTL:        time.Hour,
+		jobs:       map[string]*Job{},
+		slots:      make(chan struct{}, max(concurrency, 1)),
+		ctx:        ctx,
+		cancel:     cancel,
+		mux:        http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /jobs", s.submit)
+	s.mux.HandleFunc("GET /jobs", s.list)
+	s.mux.HandleFunc("GET /jobs/{id}", s.status)
+	s.mux.HandleFunc("DELETE /jobs/{id}", s.cancelJob)
+	s.mux.HandleFunc("GET /jobs/{id}/image", s.image)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close cancels every queued and running job and waits for them to stop.
+func (s *Server) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	frame := 0
+	if f := r.URL.Query().Get("frame"); f != "" {
+		var err error
+		if frame, err = strconv.Atoi(f); err != nil {
+			http.Error(w, "frame must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	document, err := io.ReadAll(io.LimitReader(r.Body, maxSceneSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	scene, err := g.LoadSceneWithoutFiles(bytes.NewReader(document))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.Check(scene.Frame(frame)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	job := &Job{ID: newID(), Status: Queued, Created: time.Now(), cancel: cancel}
+
+	s.mu.Lock()
+	s.expire()
+	if s.pending() >= s.MaxPending {
+		s.mu.Unlock()
+		cancel()
+		http.Error(w, "too many jobs are waiting", http.StatusServiceUnavailable)
+		return
+	}
+	s.jobs[job.ID] = job
+	snapshot := *job
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.run(ctx, job, scene, frame)
+
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, snapshot)
+}
+
+func (s *Server) run(ctx context.Context, job *Job, scene g.Scene, frame int) {
+	defer s.wg.Done()
+	defer job.cancel()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		s.finish(job, g.Canvas{}, ctx.Err())
+		return
+	}
+
+	s.update(job, func() { job.Status = Running })
+	world, camera := scene.Frame(frame)
+	image, err := camera.RenderContext(ctx, world, func(done, total int) {
+		s.update(job, func() { job.Progress = float64(done) / float64(total) })
+	})
+	s.finish(job, image.PostProcess(scene.Post), err)
+}
+
+func (s *Server) update(job *Job, change func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change()
+}
+
+func (s *Server) finish(job *Job, image g.Canvas, err error) {
+	s.update(job, func() {
+		switch {
+		case errors.Is(err, context.Canceled):
+			job.Status = Canceled
+		case err != nil:
+			job.Status = Failed
+			job.Error = err.Error()
+		default:
+			job.Status = Done
+			job.Progress = 1
+			job.image = image
+		}
+		job.finished = time.Now()
+	})
+}
+
+// pending counts the jobs that are queued or running. s.mu must be held.
+func (s *Server) pending() int {
+	n := 0
+	for _, job := range s.jobs {
+		if job.Status == Queued || job.Status == Running {
+			n++
+		}
+	}
+	return n
+}
+
+// expire forgets jobs that finished more than TTL ago. s.mu must be held.
+func (s *Server) expire() {
+	for id, job := range s.jobs {
+		if !job.finished.IsZero() && time.Since(job.finished) > s.TTL {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// lookup returns a copy of the job with the request's id, or writes a 404.
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) (*Job, Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+	job, ok := s.jobs[r.PathValue("id")]
+	if !ok {
+		http.Error(w, "no such job", http.StatusNotFound)
+		return nil, Job{}, false
+	}
+	return job, *job, true
+}
+
+func (s *Server) list(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	s.expire()
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, *job)
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(jobs, func(a, b Job) int {
+		return a.Created.Compare(b.Created)
+	})
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (s *Server) status(w http.ResponseWriter, r *http.Request) {
+	if _, job, ok := s.lookup(w, r); ok {
+		writeJSON(w, http.StatusOK, job)
+	}
+}
+
+func (s *Server) cancelJob(w http.ResponseWriter, r *http.Request) {
+	job, snapshot, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	if snapshot.Status == Queued || snapshot.Status == Running {
+		job.cancel()
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) image(w http.ResponseWriter, r *http.Request) {
+	_, job, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	if job.Status != Done {
+		http.Error(w, fmt.Sprintf("job is %s", job.Status), http.StatusConflict)
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "png":
+		var body bytes.Buffer
+		if err := job.image.WritePNG(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(body.Bytes())
+	case "ppm":
+		w.Header().Set("Content-Type", "image/x-portable-pixmap")
+		io.WriteString(w, job.image.ToPpm())
+	default:
+		http.Error(w, fmt.Sprintf("unknown format %q", format), http.StatusBadRequest)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sceneDocument(width, samples int) string {
+	return fmt.Sprintf(`{
+		"camera": {
+			"width": %d, "aspect_ratio": 2, "field_of_view": 1.0471975512,
+			"from": [0, 1.5, -5], "to": [0, 1, 0], "up": [0, 1, 0],
+			"samples": %d
+		},
+		"light": {"position": [-10, 10, -10], "intensity": [1, 1, 1]},
+		"objects": [
+			{"type": "plane"},
+			{"type": "sphere", "transform": [{"translate": [0, 1, 0]}], "material": {"color": [1, 0, 0.5]}}
+		]
+	}`, width, samples)
+}
+
+func submit(t *testing.T, server *httptest.Server, document string) Job {
+	resp, err := http.Post(server.URL+"/jobs", "application/json", strings.NewReader(document))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusAccepted)
+
+	var job Job
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+	assert.Equal(t, resp.Header.Get("Location"), "/jobs/"+job.ID)
+	return job
+}
+
+func status(t *testing.T, server *httptest.Server, id string) Job {
+	resp, err := http.Get(server.URL + "/jobs/" + id)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var job Job
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+	return job
+}
+
+func waitFor(t *testing.T, server *httptest.Server, id string, want Status) Job {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		job := status(t, server, id)
+		if job.Status == want {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s never became %s", id, want)
+	return Job{}
+}
+
+func cancel(t *testing.T, server *httptest.Server, id string) {
+	req, _ := http.NewRequest(http.MethodDelete, server.URL+"/jobs/"+id, nil)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusAccepted)
+}
+
+func newTestServer(t *testing.T, concurrency int, configure ...func(*Server)) *httptest.Server {
+	s := NewServer(concurrency)
+	for _, c := range configure {
+		c(s)
+	}
+	server := httptest.NewServer(s)
+	t.Cleanup(func() {
+		server.Close()
+		s.Close()
+	})
+	return server
+}
+
+func TestRenderingAJob(t *testing.T) {
+	server := newTestServer(t, 2)
+	job := submit(t, server, sceneDocument(20, 1))
+	assert.Equal(t, job.Status, Queued)
+
+	done := waitFor(t, server, job.ID, Done)
+	assert.Equal(t, done.Progress, 1.0)
+
+	t.Run("as a png", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/jobs/" + job.ID + "/image")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, resp.Header.Get("Content-Type"), "image/png")
+		img, err := png.Decode(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, img.Bounds().Dx(), 20)
+		assert.Equal(t, img.Bounds().Dy(), 10)
+	})
+
+	t.Run("as a ppm", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/jobs/" + job.ID + "/image?format=ppm")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var magic string
+		var width, height int
+		fmt.Fscan(resp.Body, &magic, &width, &height)
+		assert.Equal(t, []any{magic, width, height}, []any{"P3", 20, 10})
+	})
+
+	t.Run("in an unknown format", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/jobs/" + job.ID + "/image?format=gif")
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("is listed", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/jobs")
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var jobs []Job
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&jobs))
+		assert.Len(t, jobs, 1)
+		assert.Equal(t, jobs[0].ID, job.ID)
+	})
+}
+
+func TestQueueingAndCancellingJobs(t *testing.T) {
+	server := newTestServer(t, 1)
+	slow := sceneDocument(400, 16)
+
+	first := submit(t, server, slow)
+	waitFor(t, server, first.ID, Running)
+
+	second := submit(t, server, slow)
+	assert.Equal(t, status(t, server, second.ID).Status, Queued)
+
+	resp, err := http.Get(server.URL + "/jobs/" + second.ID + "/image")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusConflict)
+
+	cancel(t, server, second.ID)
+	waitFor(t, server, second.ID, Canceled)
+	assert.Equal(t, status(t, server, first.ID).Status, Running)
+
+	cancel(t, server, first.ID)
+	waitFor(t, server, first.ID, Canceled)
+}
+
+func TestRejectedRequests(t *testing.T) {
+	server := newTestServer(t, 1)
+
+	resp, err := http.Post(server.URL+"/jobs", "application/json", strings.NewReader(`{"objects": []}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+
+	resp, err = http.Post(server.URL+"/jobs?frame=first", "application/json", strings.NewReader(sceneDocument(4, 1)))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+
+	for _, document := range []string{
+		sceneDocument(5000, 1),
+		sceneDocument(4, 5000),
+		strings.Replace(sceneDocument(4, 1), `"samples": 1`, `"samples": 1, "integrator": {"type": "whitted", "max_depth": 60}`, 1),
+		strings.Replace(sceneDocument(4, 1), `"samples": 1`, `"samples": 1, "integrator": {"type": "path", "roulette_depth": 1000000}`, 1),
+		strings.Replace(sceneDocument(4, 1), `"objects"`, `"background": {"type": "environment", "path": "/etc/passwd"}, "objects"`, 1),
+	} {
+		resp, err = http.Post(server.URL+"/jobs", "application/json", strings.NewReader(document))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	}
+
+	resp, err = http.Get(server.URL + "/jobs/missing")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+}
+
+func TestFinishedJobsExpire(t *testing.T) {
+	server := newTestServer(t, 1, func(s *Server) { s.TTL = 50 * time.Millisecond })
+
+	job := submit(t, server, sceneDocument(4, 1))
+	waitFor(t, server, job.ID, Done)
+	time.Sleep(100 * time.Millisecond)
+
+	resp, err := http.Get(server.URL + "/jobs/" + job.ID)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+}
+
+func TestQueueIsCapped(t *testing.T) {
+	server := newTestServer(t, 1, func(s *Server) { s.MaxPending = 2 })
+	slow := sceneDocument(400, 16)
+
+	first := submit(t, server, slow)
+	second := submit(t, server, slow)
+
+	resp, err := http.Post(server.URL+"/jobs", "application/json", strings.NewReader(slow))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusServiceUnavailable)
+
+	cancel(t, server, second.ID)
+	waitFor(t, server, second.ID, Canceled)
+	third := submit(t, server, slow)
+
+	cancel(t, server, first.ID)
+	cancel(t, server, third.ID)
+}
@@ -1,5 +1,7 @@
 package goray
 
+import "math"
+
 type Shape interface {
 	GetMaterial() Material
 	SetMaterial(m Material)
@@ -10,10 +12,34 @@ type Shape interface {
 	LocalNormalAt(p Point) Vector
 }
 
+// MovingShape is a shape that moves from its Transform at time 0 to its end
+// transform at time 1 while the camera's shutter is open.
+type MovingShape interface {
+	Shape
+	GetEndTransform() *Matrix
+	SetEndTransform(m Matrix)
+}
+
+// TransformAt is where shape is at time. A moving shape's translation,
+// rotation and scale are interpolated separately, so a turning shape keeps
+// its size mid-turn, see Matrix.Slerp.
+func TransformAt(shape Shape, time float64) Matrix {
+	start := shape.GetTransform()
+	moving, ok := shape.(MovingShape)
+	if !ok || moving.GetEndTransform() == nil {
+		return start
+	}
+	return start.Slerp(*moving.GetEndTransform(), math.Max(0.0, math.Min(1.0, time)))
+}
+
 func NormalAt(shape Shape, point Point) Vector {
-	objectPoint := shape.GetTransform().Inverse().Mult(point)
+	return NormalAtTime(shape, point, 0.0)
+}
+
+func NormalAtTime(shape Shape, point Point, time float64) Vector {
+	transform := TransformAt(shape, time)
+	objectPoint := transform.Inverse().MulPoint(point)
 	objectNormal := shape.LocalNormalAt(objectPoint)
-	worldNormal := shape.GetTransform().Inverse().Transpose().Mult(objectNormal)
-	worldNormal.w = 0.0
+	worldNormal := transform.Inverse().Transpose().MulVector(objectNormal)
 	return worldNormal.Normalize()
 }
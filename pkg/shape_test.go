@@ -1,6 +1,7 @@
 package goray
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -107,3 +108,46 @@ func TestNormal(t *testing.T) {
 		assert.True(t, TuplesEqual(n, NewVector(0, 0.70711, -0.70711)))
 	})
 }
+
+func TestTransformAt(t *testing.T) {
+	t.Run("for a shape that does not move", func(t *testing.T) {
+		s := NewDemoShape()
+		s.SetTransform(Translation(1, 2, 3))
+		assert.True(t, MatricesEqual(TransformAt(&s, 0.5), Translation(1, 2, 3)))
+	})
+
+	t.Run("for a moving shape", func(t *testing.T) {
+		s := NewSphere()
+		s.SetEndTransform(Translation(2, 0, 0))
+
+		assert.True(t, MatricesEqual(TransformAt(&s, 0), IdentityMatrix()))
+		assert.True(t, MatricesEqual(TransformAt(&s, 0.5), Translation(1, 0, 0)))
+		assert.True(t, MatricesEqual(TransformAt(&s, 1), Translation(2, 0, 0)))
+	})
+
+	t.Run("for a half turn", func(t *testing.T) {
+		s := NewSphere()
+		s.SetEndTransform(RotationY(math.Pi))
+
+		halfway := TransformAt(&s, 0.5)
+		assert.True(t, halfway.IsInvertible())
+		assert.True(t, TuplesEqual(halfway.MulVector(NewVector(1, 0, 0)).Abs(), NewVector(0, 0, 1)))
+	})
+
+	t.Run("clamps times outside the motion", func(t *testing.T) {
+		s := NewSphere()
+		s.SetEndTransform(Translation(2, 0, 0))
+
+		assert.True(t, MatricesEqual(TransformAt(&s, -1), IdentityMatrix()))
+		assert.True(t, MatricesEqual(TransformAt(&s, 2), Translation(2, 0, 0)))
+	})
+}
+
+func TestNormalAtTime(t *testing.T) {
+	s := NewSphere()
+	s.SetEndTransform(Translation(0, 2, 0))
+
+	n := NormalAtTime(&s, NewPoint(0, 1.70711, -0.70711), 0.5)
+
+	assert.True(t, TuplesEqual(n, NewVector(0, 0.70711, -0.70711)))
+}
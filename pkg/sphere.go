@@ -3,11 +3,12 @@ package goray
 import "math"
 
 type Sphere struct {
-	Center    Point
-	Radius    float64
-	Transform Matrix
-	Material  Material
-	SavedRay  Ray
+	Center       Point
+	Radius       float64
+	Transform    Matrix
+	EndTransform *Matrix
+	Material     Material
+	SavedRay     Ray
 }
 
 func NewSphere() Sphere {
@@ -35,6 +36,14 @@ func (s *Sphere) SetMaterial(m Material) {
 	s.Material = m
 }
 
+func (s *Sphere) GetEndTransform() *Matrix {
+	return s.EndTransform
+}
+
+func (s *Sphere) SetEndTransform(m Matrix) {
+	s.EndTransform = &m
+}
+
 func (s *Sphere) GetSavedRay() Ray {
 	return s.SavedRay
 }
@@ -62,3 +71,10 @@ func (s *Sphere) LocalIntersect(r Ray) Intersections {
 func (s *Sphere) LocalNormalAt(p Point) Vector {
 	return p.Sub(NewPoint(0, 0, 0))
 }
+
+func (s *Sphere) SampleSurface(u, v float64) Point {
+	z := 1.0 - 2.0*u
+	r := math.Sqrt(math.Max(0.0, 1.0-z*z))
+	phi := 2.0 * math.Pi * v
+	return NewPoint(r*math.Cos(phi), r*math.Sin(phi), z)
+}
@@ -82,3 +82,12 @@ func TestNormalOnTransformedSphere(t *testing.T) {
 		assert.True(t, TuplesEqual(n, NewVector(0, 0.97014, -0.24254)))
 	})
 }
+
+func TestSphereSampleSurface(t *testing.T) {
+	s := NewSphere()
+
+	assert.True(t, TuplesEqual(s.SampleSurface(0, 0), NewPoint(0, 0, 1)))
+	assert.True(t, TuplesEqual(s.SampleSurface(1, 0), NewPoint(0, 0, -1)))
+	assert.True(t, TuplesEqual(s.SampleSurface(0.5, 0.25), NewPoint(0, 1, 0)))
+	assert.InDelta(t, s.SampleSurface(0.3, 0.7).Sub(NewPoint(0, 0, 0)).Magnitude(), 1.0, 0.00001)
+}
@@ -0,0 +1,154 @@
+package goray
+
+import "math"
+
+// float32Epsilon is the relative rounding error of a float32.
+const float32Epsilon = 1.0 / (1 << 24)
+
+// sphereBatch keeps a float32 copy of the inverse transforms of a world's
+// spheres, laid out structure-of-arrays: one slice per matrix entry, so a
+// ray is tested against every sphere by walking a dozen contiguous slices in
+// step.
+//
+// The float32 test is only a prefilter. It rules a sphere out only when the
+// ray misses by more than float32 rounding could account for, and rules
+// nothing out for rays or spheres so far from the origin that it cannot
+// bound that rounding. Every sphere it keeps is intersected in float64
+// exactly as before, using an inverse computed once rather than once per
+// ray, so renders come out the same as without it.
+//
+// Moving spheres, spheres that cannot be inverted and every other kind of
+// shape are left out and intersected as usual.
+type sphereBatch struct {
+	objects []Shape
+	// slots gives each of objects its place in the batch, or -1.
+	slots    []int
+	spheres  []*Sphere
+	inverses []Matrix
+
+	// m holds the top three rows of each inverse, m[row*4+col][sphere].
+	m [12][]float32
+	// linear and affine bound the size of each inverse's rows, without and
+	// with the translation, which bounds the rounding in the local ray.
+	linear []float32
+	affine []float32
+}
+
+// newSphereBatch batches the spheres among objects. When previous is a batch
+// of an earlier version of objects, the spheres that kept their index and
+// that unchanged reports true for reuse the inverses worked out for it.
+func newSphereBatch(objects []Shape, previous *sphereBatch, unchanged func(i int) bool) *sphereBatch {
+	b := &sphereBatch{objects: objects, slots: make([]int, len(objects))}
+	for i, object := range objects {
+		b.slots[i] = -1
+		s, ok := object.(*Sphere)
+		if !ok || s.EndTransform != nil {
+			continue
+		}
+		inverse, ok := previous.inverse(i, unchanged)
+		if !ok {
+			inverse, ok = s.Transform.TryInverse()
+		}
+		if !ok {
+			continue
+		}
+
+		b.slots[i] = len(b.spheres)
+		b.spheres = append(b.spheres, s)
+		b.inverses = append(b.inverses, inverse)
+		var linear, affine float64
+		for row := range 3 {
+			sum := math.Abs(inverse.At(row, 0)) + math.Abs(inverse.At(row, 1)) + math.Abs(inverse.At(row, 2))
+			linear = math.Max(linear, sum)
+			affine = math.Max(affine, sum+math.Abs(inverse.At(row, 3)))
+		}
+		for k := range b.m {
+			b.m[k] = append(b.m[k], float32(inverse[k]))
+		}
+		b.linear = append(b.linear, float32(linear))
+		b.affine = append(b.affine, float32(affine))
+	}
+	if len(b.spheres) == 0 {
+		return nil
+	}
+	return b
+}
+
+// inverse returns the inverse b holds for the object at index i, if b has one
+// and unchanged says the object is the same.
+func (b *sphereBatch) inverse(i int, unchanged func(i int) bool) (Matrix, bool) {
+	if b == nil || i >= len(b.slots) || b.slots[i] < 0 || !unchanged(i) {
+		return Matrix{}, false
+	}
+	return b.inverses[b.slots[i]], true
+}
+
+// covers reports whether b was built from objects.
+func (b *sphereBatch) covers(objects []Shape) bool {
+	return b != nil && len(b.objects) == len(objects) && (len(objects) == 0 || &b.objects[0] == &objects[0])
+}
+
+// misses sets bit k of the returned mask when ray certainly misses sphere k,
+// reusing mask's storage when it is big enough.
+func (b *sphereBatch) misses(ray Ray, mask []uint64) []uint64 {
+	n := len(b.spheres)
+	words := (n + 63) / 64
+	if cap(mask) < words {
+		mask = make([]uint64, words)
+	}
+	mask = mask[:words]
+	clear(mask)
+
+	ox, oy, oz := float32(ray.Origin.x), float32(ray.Origin.y), float32(ray.Origin.z)
+	dx, dy, dz := float32(ray.Direction.x), float32(ray.Direction.y), float32(ray.Direction.z)
+	originSize := max(1, abs32(ox), abs32(oy), abs32(oz))
+	directionSize := max(abs32(dx), abs32(dy), abs32(dz))
+
+	m0, m1, m2, m3 := b.m[0][:n], b.m[1][:n], b.m[2][:n], b.m[3][:n]
+	m4, m5, m6, m7 := b.m[4][:n], b.m[5][:n], b.m[6][:n], b.m[7][:n]
+	m8, m9, m10, m11 := b.m[8][:n], b.m[9][:n], b.m[10][:n], b.m[11][:n]
+	linear, affine := b.linear[:n], b.affine[:n]
+
+	for k := range n {
+		lox := m0[k]*ox + m1[k]*oy + m2[k]*oz + m3[k]
+		loy := m4[k]*ox + m5[k]*oy + m6[k]*oz + m7[k]
+		loz := m8[k]*ox + m9[k]*oy + m10[k]*oz + m11[k]
+		ldx := m0[k]*dx + m1[k]*dy + m2[k]*dz
+		ldy := m4[k]*dx + m5[k]*dy + m6[k]*dz
+		ldz := m8[k]*dx + m9[k]*dy + m10[k]*dz
+
+		a := ldx*ldx + ldy*ldy + ldz*ldz
+		halfB := ldx*lox + ldy*loy + ldz*loz
+		c := lox*lox + loy*loy + loz*loz
+
+		// a*c - halfB² is a times the squared distance from the sphere's
+		// centre to the ray's line, so the ray misses when it is more than
+		// a. The margin covers float32 rounding in the local ray: a fixed
+		// share for the direction, which is only trusted when its own
+		// rounding is small, plus however far the origin may have moved.
+		originError := 8 * float32Epsilon * affine[k] * originSize
+		directionError := 8 * float32Epsilon * linear[k] * directionSize
+		margin := (4e-3 + 8*originError) * (1 + c)
+		if a > 0 && originError < 0.1 && directionError*directionError < 1e-6*a && a*c-halfB*halfB > a*(1+margin) {
+			mask[k/64] |= 1 << (k % 64)
+		}
+	}
+	return mask
+}
+
+// intersect intersects ray with objects[i], skipping spheres that missed
+// has ruled out.
+func (b *sphereBatch) intersect(ray Ray, i int, missed []uint64) Intersections {
+	k := b.slots[i]
+	if k < 0 {
+		return ray.Intersect(b.objects[i])
+	}
+	if missed[k/64]&(1<<(k%64)) != 0 {
+		return nil
+	}
+	return b.spheres[k].LocalIntersect(ray.Transform(b.inverses[k]))
+}
+
+func abs32(f float32) float32 {
+	return math.Float32frombits(math.Float32bits(f) &^ (1 << 31))
+}
@@ -0,0 +1,159 @@
+package goray
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSphereBatch(t *testing.T) {
+	t.Run("only batches still spheres", func(t *testing.T) {
+		still := NewSphere()
+		moving := NewSphere()
+		moving.SetEndTransform(Translation(1, 0, 0))
+		flat := NewSphere()
+		flat.SetTransform(Scaling(1, 0, 1))
+		plane := NewPlane()
+
+		b := newSphereBatch([]Shape{&plane, &still, &moving, &flat}, nil, nil)
+		assert.Equal(t, b.slots, []int{-1, 0, -1, -1})
+	})
+
+	t.Run("is left out of worlds without spheres", func(t *testing.T) {
+		plane := NewPlane()
+		assert.Nil(t, newSphereBatch([]Shape{&plane}, nil, nil))
+	})
+
+	t.Run("is ignored once the world's objects change", func(t *testing.T) {
+		w := defaultWorld().prepared()
+		assert.True(t, w.spheres.covers(w.Objects))
+
+		w.Objects = append([]Shape{}, w.Objects...)
+		assert.False(t, w.spheres.covers(w.Objects))
+	})
+}
+
+func TestPrepareChanged(t *testing.T) {
+	scene := func(y float64) Scene {
+		w := sphereField(2)
+		w.Objects[2].SetTransform(Translation(0, y, 0))
+		return Scene{World: w}
+	}
+	before, after := scene(0), scene(1)
+	diff := DiffScenes(before, after)
+	assert.Equal(t, diff.Objects, []int{2})
+
+	previous := before.World.Prepare()
+	// Stand-ins that only survive if they are carried over.
+	for k := range previous.spheres.inverses {
+		previous.spheres.inverses[k] = Scaling(2, 2, 2)
+	}
+	prepared := after.World.PrepareChanged(previous, diff)
+
+	for i, object := range after.World.Objects {
+		k := prepared.spheres.slots[i]
+		if k < 0 {
+			continue
+		}
+		if i == 2 {
+			assert.Equal(t, prepared.spheres.inverses[k], object.GetTransform().Inverse())
+		} else {
+			assert.Equal(t, prepared.spheres.inverses[k], Scaling(2, 2, 2))
+		}
+	}
+
+	t.Run("starts again once objects are added", func(t *testing.T) {
+		grown := scene(0)
+		grown.World.Objects = append(grown.World.Objects, grown.World.Objects[1])
+		prepared := grown.World.PrepareChanged(previous, DiffScenes(before, grown))
+		assert.Equal(t, prepared.Intersect(fieldRays()[0]), grown.World.Intersect(fieldRays()[0]))
+		for _, inverse := range prepared.spheres.inverses {
+			assert.NotEqual(t, inverse, Scaling(2, 2, 2))
+		}
+	})
+}
+
+func TestBatchedIntersectionsMatch(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	randomVector := func(scale float64) Vector {
+		return NewVector(rng.Float64()*2-1, rng.Float64()*2-1, rng.Float64()*2-1).Mul(scale)
+	}
+
+	far := NewSphere()
+	far.SetTransform(Translation(1e5, -3e4, 2e5).Mul(Scaling(0.01, 0.01, 0.01)))
+	tilted := NewSphere()
+	tilted.SetTransform(RotationZ(0.3).Mul(Scaling(3, 0.2, 1)))
+	w := sphereField(12)
+	w.Objects = append(w.Objects, &far, &tilted)
+	batched := w.prepared()
+
+	rays := fieldRays()
+	for range 2000 {
+		rays = append(rays, NewRay(NewPoint(0, 0, 0).Add(randomVector(12)), randomVector(1)))
+	}
+	// Rays that only just graze a sphere, from either side.
+	for _, object := range w.Objects[1:] {
+		m := object.GetTransform()
+		for range 20 {
+			direction := randomVector(1).Normalize()
+			side := direction.Cross(randomVector(1)).Normalize().Mul(1 + (rng.Float64()*2-1)*1e-6)
+			origin := NewPoint(0, 0, 0).Add(side).Add(direction.Mul(-5))
+			rays = append(rays, NewRay(m.MulPoint(origin), m.MulVector(direction)))
+		}
+	}
+
+	for _, ray := range rays {
+		assert.Equal(t, batched.Intersect(ray), w.Intersect(ray))
+	}
+}
+
+func TestBatchKeepsGrazingHitsOnLargeDistantSpheres(t *testing.T) {
+	for _, m := range []Matrix{
+		Translation(3e5, 1e3, 8e5).Mul(Scaling(1e4, 1e4, 1e4)),
+		Translation(-2e3, 50, 4e3).Mul(Scaling(500, 500, 500)),
+		Translation(1e7, 0, 1e7).Mul(Scaling(1e6, 1e6, 1e6)),
+	} {
+		big := NewSphere()
+		big.SetTransform(m)
+		w := World{Objects: []Shape{&big}}
+		batched := w.prepared()
+		assert.NotNil(t, batched.spheres)
+
+		hits := 0
+		// Rays from the origin aimed just inside and just outside the
+		// sphere's silhouette, where float32 and float64 disagree most.
+		centre := m.MulPoint(NewPoint(0, 0, 0))
+		toCentre := centre.Sub(NewPoint(0, 0, 0))
+		distance := toCentre.Magnitude()
+		radius := m.At(0, 0)
+		side := toCentre.Cross(NewVector(0, 1, 0)).Normalize()
+		for _, offset := range []float64{-1e-3, -1e-6, -1e-9, 0, 1e-9, 1e-6} {
+			edge := radius * (1 + offset) * distance / math.Sqrt(distance*distance-radius*radius)
+			ray := NewRay(NewPoint(0, 0, 0), toCentre.Normalize().Add(side.Mul(edge/distance)))
+			expected := ray.Intersect(&big)
+			assert.Equal(t, batched.Intersect(ray), w.Intersect(ray))
+			hits += len(expected)
+		}
+		assert.Greater(t, hits, 0)
+
+		// The prefilter is still at work on rays that clearly miss.
+		clear := NewRay(NewPoint(0, 0, 0), toCentre.Normalize().Add(side.Mul(20*radius/distance)))
+		assert.NotZero(t, batched.spheres.misses(clear, nil)[0])
+	}
+}
+
+func TestPreparedWorldsRenderTheSame(t *testing.T) {
+	w := sphereField(4)
+	c := NewCamera(16, 1, math.Pi/3)
+	c.Transform = NewViewTransform(NewPoint(0, 3, -6), NewPoint(0, 0, 2), NewVector(0, 1, 0))
+
+	expected := blankCanvas(c.Width, c.Height)
+	for y := range c.Height {
+		for x := range c.Width {
+			expected.Write(x, y, c.ColorForPixel(w, x, y, nil))
+		}
+	}
+	assert.Equal(t, c.RenderTile(w, 0, 0, c.Width, c.Height), expected)
+}
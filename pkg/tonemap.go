@@ -0,0 +1,88 @@
+package goray
+
+import (
+	"fmt"
+	"math"
+)
+
+type ToneMap int
+
+const (
+	ClampToneMap ToneMap = iota
+	ReinhardToneMap
+	ACESToneMap
+)
+
+var toneMaps = map[string]ToneMap{
+	"clamp":    ClampToneMap,
+	"reinhard": ReinhardToneMap,
+	"aces":     ACESToneMap,
+}
+
+func ParseToneMap(name string) (ToneMap, error) {
+	tm, ok := toneMaps[name]
+	if !ok {
+		return ClampToneMap, fmt.Errorf("unknown tone map %q", name)
+	}
+	return tm, nil
+}
+
+func (tm ToneMap) Apply(c Color) Color {
+	switch tm {
+	case ReinhardToneMap:
+		return NewColor(reinhard(c.x), reinhard(c.y), reinhard(c.z))
+	case ACESToneMap:
+		return NewColor(aces(c.x), aces(c.y), aces(c.z))
+	default:
+		return c
+	}
+}
+
+func reinhard(x float64) float64 {
+	x = math.Max(x, 0.0)
+	return x / (1.0 + x)
+}
+
+// aces is Krzysztof Narkowicz's curve fit of the ACES filmic tone mapping
+// reference transform.
+func aces(x float64) float64 {
+	x = math.Max(x, 0.0)
+	return math.Min((x*(2.51*x+0.03))/(x*(2.43*x+0.59)+0.14), 1.0)
+}
+
+// LinearToSRGB applies the sRGB transfer function to a linear value in [0, 1].
+func LinearToSRGB(x float64) float64 {
+	if x <= 0.0031308 {
+		return 12.92 * x
+	}
+	return 1.055*math.Pow(x, 1.0/2.4) - 0.055
+}
+
+// PostProcess maps the linear radiance a camera renders into display values.
+// Exposure is in stops, so every step of 1 doubles the brightness. The zero
+// value leaves colors as they are, matching the plain clamp of the encoders.
+type PostProcess struct {
+	Exposure float64
+	ToneMap  ToneMap
+	SRGB     bool
+}
+
+func (p PostProcess) Apply(c Color) Color {
+	c = p.ToneMap.Apply(c.Mul(math.Exp2(p.Exposure)))
+	if p.SRGB {
+		c = NewColor(
+			LinearToSRGB(math.Max(0.0, math.Min(c.x, 1.0))),
+			LinearToSRGB(math.Max(0.0, math.Min(c.y, 1.0))),
+			LinearToSRGB(math.Max(0.0, math.Min(c.z, 1.0))),
+		)
+	}
+	return c
+}
+
+func (c Canvas) PostProcess(p PostProcess) Canvas {
+	out := blankCanvas(c.Width, c.Height)
+	for i, pixel := range c.Pixels {
+		out.Pixels[i] = p.Apply(pixel)
+	}
+	return out
+}
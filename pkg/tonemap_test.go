@@ -0,0 +1,78 @@
+package goray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToneMaps(t *testing.T) {
+	testCases := []struct {
+		description string
+		toneMap     ToneMap
+		in          Color
+		expected    Color
+	}{
+		{"clamp leaves colors alone", ClampToneMap, NewColor(0.5, 2, -1), NewColor(0.5, 2, -1)},
+		{"reinhard compresses highlights", ReinhardToneMap, NewColor(0, 1, 3), NewColor(0, 0.5, 0.75)},
+		{"reinhard ignores negative values", ReinhardToneMap, NewColor(-1, 0, 0), NewColor(0, 0, 0)},
+		{"aces maps black to black", ACESToneMap, Black(), Black()},
+		{"aces saturates bright values", ACESToneMap, NewColor(1000, 1000, 1000), NewColor(1, 1, 1)},
+		{"aces maps mid grey", ACESToneMap, NewColor(0.18, 0.18, 0.18), NewColor(0.26690, 0.26690, 0.26690)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.True(t, TuplesEqual(tc.toneMap.Apply(tc.in), tc.expected))
+		})
+	}
+}
+
+func TestParseToneMap(t *testing.T) {
+	tm, err := ParseToneMap("reinhard")
+	assert.NoError(t, err)
+	assert.Equal(t, tm, ReinhardToneMap)
+
+	_, err = ParseToneMap("filmic")
+	assert.Error(t, err)
+}
+
+func TestLinearToSRGB(t *testing.T) {
+	assert.Equal(t, LinearToSRGB(0), 0.0)
+	assert.InDelta(t, LinearToSRGB(0.002), 0.02584, 1e-5)
+	assert.InDelta(t, LinearToSRGB(0.18), 0.46135, 1e-5)
+	assert.InDelta(t, LinearToSRGB(1), 1.0, 1e-9)
+}
+
+func TestPostProcess(t *testing.T) {
+	t.Run("the zero value is the identity", func(t *testing.T) {
+		c := NewColor(0.2, 1.5, -0.1)
+		assert.Equal(t, PostProcess{}.Apply(c), c)
+	})
+
+	t.Run("exposure is measured in stops", func(t *testing.T) {
+		p := PostProcess{Exposure: 2}
+		assert.True(t, TuplesEqual(p.Apply(NewColor(0.1, 0.2, 0.25)), NewColor(0.4, 0.8, 1)))
+	})
+
+	t.Run("exposure is applied before tone mapping", func(t *testing.T) {
+		p := PostProcess{Exposure: 1, ToneMap: ReinhardToneMap}
+		assert.True(t, TuplesEqual(p.Apply(NewColor(0.5, 0.5, 0.5)), NewColor(0.5, 0.5, 0.5)))
+	})
+
+	t.Run("srgb encodes the clamped result", func(t *testing.T) {
+		p := PostProcess{SRGB: true}
+		c := p.Apply(NewColor(0.18, 4, -1))
+		assert.InDelta(t, c.x, 0.46135, 1e-5)
+		assert.InDelta(t, c.y, 1.0, 1e-9)
+		assert.Equal(t, c.z, 0.0)
+	})
+
+	t.Run("canvases are processed into a copy", func(t *testing.T) {
+		c := blankCanvas(2, 1)
+		c.Write(1, 0, NewColor(1, 1, 1))
+		out := c.PostProcess(PostProcess{ToneMap: ReinhardToneMap})
+		assert.True(t, TuplesEqual(out.At(1, 0), NewColor(0.5, 0.5, 0.5)))
+		assert.Equal(t, c.At(1, 0), NewColor(1, 1, 1))
+	})
+}
@@ -1,23 +1,75 @@
 package goray
 
-import "math"
-
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Tuple is a general four component value. Point, Vector and Color share
+// its layout but each only has the operations that make sense for it; a
+// Tuple is mostly what they become when multiplied by a matrix.
 type Tuple struct {
 	x, y, z, w float64
 }
-type Point = Tuple
-type Vector = Tuple
+
+// Epsilon is a sensible default tolerance for ApproxEqual.
+const Epsilon = 0.00001
 
 func NewTuple(x, y, z, w float64) Tuple {
 	return Tuple{x: x, y: y, z: z, w: w}
 }
 
-func NewPoint(x, y, z float64) Point {
-	return NewTuple(x, y, z, 1)
+func (t Tuple) X() float64 {
+	return t.x
+}
+
+func (t Tuple) Y() float64 {
+	return t.y
+}
+
+func (t Tuple) Z() float64 {
+	return t.z
+}
+
+func (t Tuple) W() float64 {
+	return t.w
+}
+
+// ApproxEqual reports whether every component of u is within epsilon of the
+// same component of v.
+func (u Tuple) ApproxEqual(v Tuple, epsilon float64) bool {
+	return math.Abs(u.x-v.x) < epsilon &&
+		math.Abs(u.y-v.y) < epsilon &&
+		math.Abs(u.z-v.z) < epsilon &&
+		math.Abs(u.w-v.w) < epsilon
+}
+
+func (t Tuple) String() string {
+	return fmt.Sprintf("(%g, %g, %g, %g)", t.x, t.y, t.z, t.w)
+}
+
+// MarshalJSON writes the tuple as the array [x, y, z, w].
+func (t Tuple) MarshalJSON() ([]byte, error) {
+	return json.Marshal([4]float64{t.x, t.y, t.z, t.w})
 }
 
-func NewVector(x, y, z float64) Point {
-	return NewTuple(x, y, z, 0)
+// UnmarshalJSON reads an array of three or four numbers. When there are only
+// three, w is 0.
+func (t *Tuple) UnmarshalJSON(data []byte) error {
+	var components []float64
+	if err := json.Unmarshal(data, &components); err != nil {
+		return err
+	}
+	switch len(components) {
+	case 3:
+		*t = NewTuple(components[0], components[1], components[2], 0)
+	case 4:
+		*t = NewTuple(components[0], components[1], components[2], components[3])
+	default:
+		return fmt.Errorf("tuple needs 3 or 4 components, got %d", len(components))
+	}
+	return nil
 }
 
 func (t Tuple) IsPoint() bool {
@@ -61,13 +113,48 @@ func (u Tuple) Dot(v Tuple) float64 {
 }
 
 func (u Tuple) Cross(v Tuple) Tuple {
-	return NewVector(
+	return NewTuple(
 		u.y*v.z-u.z*v.y,
 		u.z*v.x-u.x*v.z,
 		u.x*v.y-u.y*v.x,
+		0,
 	)
 }
 
 func (u Tuple) Reflect(n Tuple) Tuple {
 	return u.Sub(n.Mul(2.0 * u.Dot(n)))
 }
+
+func (u Tuple) Min(v Tuple) Tuple {
+	return NewTuple(math.Min(u.x, v.x), math.Min(u.y, v.y), math.Min(u.z, v.z), math.Min(u.w, v.w))
+}
+
+func (u Tuple) Max(v Tuple) Tuple {
+	return NewTuple(math.Max(u.x, v.x), math.Max(u.y, v.y), math.Max(u.z, v.z), math.Max(u.w, v.w))
+}
+
+func (u Tuple) Abs() Tuple {
+	return NewTuple(math.Abs(u.x), math.Abs(u.y), math.Abs(u.z), math.Abs(u.w))
+}
+
+// Lerp moves from u towards v by t, giving u at 0 and v at 1.
+func (u Tuple) Lerp(v Tuple, t float64) Tuple {
+	return u.Add(v.Sub(u).Mul(t))
+}
+
+// marshalTriple and unmarshalTriple read and write the three component
+// arrays points, vectors and colors are stored as.
+func marshalTriple(x, y, z float64) ([]byte, error) {
+	return json.Marshal([3]float64{x, y, z})
+}
+
+func unmarshalTriple(data []byte, kind string) (x, y, z float64, err error) {
+	var components []float64
+	if err := json.Unmarshal(data, &components); err != nil {
+		return 0, 0, 0, err
+	}
+	if len(components) != 3 {
+		return 0, 0, 0, fmt.Errorf("%s needs 3 components, got %d", kind, len(components))
+	}
+	return components[0], components[1], components[2], nil
+}
@@ -1,18 +1,15 @@
 package goray
 
 import (
+	"encoding/json"
 	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TuplesEqual(a, b Tuple) bool {
-	ε := 0.00001
-	return math.Abs(a.x-b.x) < ε &&
-		math.Abs(a.y-b.y) < ε &&
-		math.Abs(a.z-b.z) < ε &&
-		math.Abs(a.w-b.w) < ε
+func TuplesEqual[T Tuple | Point | Vector | Color](a, b T) bool {
+	return Tuple(a).ApproxEqual(Tuple(b), Epsilon)
 }
 
 func TestTuplePredicates(t *testing.T) {
@@ -33,12 +30,12 @@ func TestTuplePredicates(t *testing.T) {
 
 func TestNewPoint(t *testing.T) {
 	p := NewPoint(4, -4, 3)
-	assert.True(t, p.IsPoint())
+	assert.True(t, Tuple(p).IsPoint())
 }
 
 func TestNewVector(t *testing.T) {
 	p := NewVector(4, -4, 3)
-	assert.True(t, p.IsVector())
+	assert.True(t, Tuple(p).IsVector())
 }
 
 func TestAddingTuples(t *testing.T) {
@@ -56,11 +53,11 @@ func TestSubtractingTuples(t *testing.T) {
 		assert.True(t, TuplesEqual(p1.Sub(p2), NewVector(-2, -4, -6)))
 	})
 
-	t.Run("subtracting a vector from a point", func(t *testing.T) {
+	t.Run("moving a point back along a vector", func(t *testing.T) {
 		p := NewPoint(3, 2, 1)
 		v := NewVector(5, 6, 7)
 
-		assert.True(t, TuplesEqual(p.Sub(v), NewPoint(-2, -4, -6)))
+		assert.True(t, TuplesEqual(p.Add(v.Neg()), NewPoint(-2, -4, -6)))
 	})
 
 	t.Run("subtracting two vectors", func(t *testing.T) {
@@ -95,7 +92,7 @@ func TestDividingTuples(t *testing.T) {
 }
 
 func TestMagnitude(t *testing.T) {
-	testCases := map[Tuple]float64{
+	testCases := map[Vector]float64{
 		NewVector(1, 0, 0):    1,
 		NewVector(0, 1, 0):    1,
 		NewVector(0, 0, 1):    1,
@@ -153,3 +150,102 @@ func TestReflect(t *testing.T) {
 		assert.True(t, TuplesEqual(r, NewVector(1, 0, 0)))
 	})
 }
+
+func TestTupleJSON(t *testing.T) {
+	t.Run("marshals all four components of a tuple", func(t *testing.T) {
+		data, err := json.Marshal(NewTuple(1, -2.5, 3, 1))
+		assert.NoError(t, err)
+		assert.Equal(t, string(data), "[1,-2.5,3,1]")
+	})
+
+	t.Run("marshals points, vectors and colors as three components", func(t *testing.T) {
+		data, err := json.Marshal([]any{NewPoint(1, 2, 3), NewVector(4, 5, 6), NewColor(0.5, 0, 1)})
+		assert.NoError(t, err)
+		assert.Equal(t, string(data), "[[1,2,3],[4,5,6],[0.5,0,1]]")
+	})
+
+	t.Run("round trips inside other values", func(t *testing.T) {
+		light := NewPointLight(NewPoint(-10, 10, -10), NewColor(0.5, 0.25, 1))
+		data, err := json.Marshal(light)
+		assert.NoError(t, err)
+
+		var decoded PointLight
+		assert.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, decoded, light)
+	})
+
+	t.Run("reads a tuple of three components with w of 0", func(t *testing.T) {
+		var tuple Tuple
+		assert.NoError(t, json.Unmarshal([]byte("[0.1, 0.2, 0.3]"), &tuple))
+		assert.Equal(t, tuple, NewTuple(0.1, 0.2, 0.3, 0))
+	})
+
+	t.Run("reads points with w of 1", func(t *testing.T) {
+		var p Point
+		assert.NoError(t, json.Unmarshal([]byte("[0.1, 0.2, 0.3]"), &p))
+		assert.Equal(t, p, NewPoint(0.1, 0.2, 0.3))
+	})
+
+	t.Run("rejects other lengths", func(t *testing.T) {
+		var tuple Tuple
+		assert.Error(t, json.Unmarshal([]byte("[1, 2]"), &tuple))
+		var p Point
+		assert.Error(t, json.Unmarshal([]byte("[1, 2, 3, 1]"), &p))
+		assert.Error(t, json.Unmarshal([]byte(`{"x": 1}`), &p))
+	})
+}
+
+func TestTupleAccessors(t *testing.T) {
+	tuple := NewTuple(4.3, -4.2, 3.1, 1)
+	assert.Equal(t, []float64{tuple.X(), tuple.Y(), tuple.Z(), tuple.W()}, []float64{4.3, -4.2, 3.1, 1})
+
+	p := NewPoint(4.3, -4.2, 3.1)
+	assert.Equal(t, []float64{p.X(), p.Y(), p.Z()}, []float64{4.3, -4.2, 3.1})
+
+	c := NewColor(-0.5, 0.4, 1.7)
+	assert.Equal(t, []float64{c.R(), c.G(), c.B()}, []float64{-0.5, 0.4, 1.7})
+}
+
+func TestTupleApproxEqual(t *testing.T) {
+	a := NewPoint(1, 2, 3)
+
+	assert.True(t, a.ApproxEqual(NewPoint(1.000001, 2, 3), Epsilon))
+	assert.False(t, a.ApproxEqual(NewPoint(1.001, 2, 3), Epsilon))
+	assert.True(t, a.ApproxEqual(NewPoint(1.001, 2, 3), 0.01))
+	assert.False(t, Tuple(a).ApproxEqual(Tuple(NewVector(1, 2, 3)), Epsilon))
+}
+
+func TestTupleComponentwise(t *testing.T) {
+	a := NewTuple(1, -2, 3, 0)
+	b := NewTuple(-1, 5, 3, 1)
+
+	assert.Equal(t, a.Min(b), NewTuple(-1, -2, 3, 0))
+	assert.Equal(t, a.Max(b), NewTuple(1, 5, 3, 1))
+	assert.Equal(t, a.Abs(), NewTuple(1, 2, 3, 0))
+}
+
+func TestTupleLerp(t *testing.T) {
+	a := NewPoint(0, 10, -2)
+	b := NewPoint(4, 20, 2)
+
+	assert.Equal(t, a.Lerp(b, 0), a)
+	assert.Equal(t, a.Lerp(b, 1), b)
+	assert.Equal(t, a.Lerp(b, 0.25), NewPoint(1, 12.5, -1))
+}
+
+func TestTupleString(t *testing.T) {
+	assert.Equal(t, NewTuple(1, -2.5, 0, 1).String(), "(1, -2.5, 0, 1)")
+	assert.Equal(t, NewPoint(1, -2.5, 0).String(), "point(1, -2.5, 0)")
+	assert.Equal(t, NewVector(0, 1, 0).String(), "vector(0, 1, 0)")
+	assert.Equal(t, NewColor(0.25, 0.5, 1).String(), "color(0.25, 0.5, 1)")
+}
+
+func TestPointArithmetic(t *testing.T) {
+	p := NewPoint(3, 2, 1)
+	v := NewVector(5, 6, 7)
+
+	assert.Equal(t, p.Add(v), NewPoint(8, 8, 8))
+	assert.Equal(t, p.Add(v).Sub(p), v)
+	assert.True(t, Tuple(p.Add(v)).IsPoint())
+	assert.True(t, Tuple(p.Sub(NewPoint(0, 0, 0))).IsVector())
+}
@@ -0,0 +1,102 @@
+package goray
+
+import (
+	"fmt"
+	"math"
+)
+
+// Vector is a direction and length, such as a ray's direction or a surface
+// normal.
+type Vector Tuple
+
+func NewVector(x, y, z float64) Vector {
+	return Vector{x: x, y: y, z: z, w: 0}
+}
+
+func (v Vector) X() float64 {
+	return v.x
+}
+
+func (v Vector) Y() float64 {
+	return v.y
+}
+
+func (v Vector) Z() float64 {
+	return v.z
+}
+
+func (u Vector) Add(v Vector) Vector {
+	return NewVector(u.x+v.x, u.y+v.y, u.z+v.z)
+}
+
+func (u Vector) Sub(v Vector) Vector {
+	return NewVector(u.x-v.x, u.y-v.y, u.z-v.z)
+}
+
+func (u Vector) Neg() Vector {
+	return NewVector(-u.x, -u.y, -u.z)
+}
+
+func (u Vector) Mul(t float64) Vector {
+	return NewVector(u.x*t, u.y*t, u.z*t)
+}
+
+func (u Vector) Div(t float64) Vector {
+	return u.Mul(1 / t)
+}
+
+func (u Vector) Magnitude() float64 {
+	return math.Sqrt(u.Dot(u))
+}
+
+func (u Vector) Normalize() Vector {
+	return u.Div(u.Magnitude())
+}
+
+func (u Vector) Dot(v Vector) float64 {
+	return u.x*v.x + u.y*v.y + u.z*v.z
+}
+
+func (u Vector) Cross(v Vector) Vector {
+	return Vector(Tuple(u).Cross(Tuple(v)))
+}
+
+func (u Vector) Reflect(n Vector) Vector {
+	return u.Sub(n.Mul(2.0 * u.Dot(n)))
+}
+
+func (u Vector) ApproxEqual(v Vector, epsilon float64) bool {
+	return Tuple(u).ApproxEqual(Tuple(v), epsilon)
+}
+
+func (u Vector) Min(v Vector) Vector {
+	return Vector(Tuple(u).Min(Tuple(v)))
+}
+
+func (u Vector) Max(v Vector) Vector {
+	return Vector(Tuple(u).Max(Tuple(v)))
+}
+
+func (u Vector) Abs() Vector {
+	return Vector(Tuple(u).Abs())
+}
+
+func (u Vector) Lerp(v Vector, t float64) Vector {
+	return u.Add(v.Sub(u).Mul(t))
+}
+
+func (u Vector) String() string {
+	return fmt.Sprintf("vector(%g, %g, %g)", u.x, u.y, u.z)
+}
+
+func (u Vector) MarshalJSON() ([]byte, error) {
+	return marshalTriple(u.x, u.y, u.z)
+}
+
+func (u *Vector) UnmarshalJSON(data []byte) error {
+	x, y, z, err := unmarshalTriple(data, "vector")
+	if err == nil {
+		*u = NewVector(x, y, z)
+	}
+	return err
+}
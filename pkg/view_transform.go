@@ -14,3 +14,20 @@ func NewViewTransform(from, to Point, up Vector) Matrix {
 
 	return m.Mul(Translation(-from.x, -from.y, -from.z))
 }
+
+// LookAt places an object at from and turns it so its -z axis points at to
+// and its y axis is as close to up as it can be, the way NewViewTransform
+// points a camera. Unlike the view transform it never scales, even when up
+// is not at right angles to the line of sight.
+func LookAt(from, to Point, up Vector) Matrix {
+	forward := to.Sub(from).Normalize()
+	left := forward.Cross(up.Normalize()).Normalize()
+	trueUp := left.Cross(forward)
+
+	return NewMatrix(
+		left.x, trueUp.x, -forward.x, from.x,
+		left.y, trueUp.y, -forward.y, from.y,
+		left.z, trueUp.z, -forward.z, from.z,
+		0, 0, 0, 1,
+	)
+}
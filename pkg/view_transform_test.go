@@ -53,3 +53,32 @@ func TestViewTransform(t *testing.T) {
 	}
 
 }
+
+func TestLookAt(t *testing.T) {
+	t.Run("for the default orientation", func(t *testing.T) {
+		m := LookAt(NewPoint(0, 0, 0), NewPoint(0, 0, -1), NewVector(0, 1, 0))
+		assert.True(t, MatricesEqual(m, IdentityMatrix()))
+	})
+
+	t.Run("places the object and points its -z axis at the target", func(t *testing.T) {
+		from, to := NewPoint(1, 3, 2), NewPoint(4, -2, 8)
+		m := LookAt(from, to, NewVector(1, 1, 0))
+
+		assert.True(t, TuplesEqual(m.MulPoint(NewPoint(0, 0, 0)), from))
+		assert.True(t, TuplesEqual(m.MulVector(NewVector(0, 0, -1)), to.Sub(from).Normalize()))
+	})
+
+	t.Run("does not scale", func(t *testing.T) {
+		m := LookAt(NewPoint(1, 3, 2), NewPoint(4, -2, 8), NewVector(1, 1, 0))
+
+		d, ok := m.Decompose()
+		assert.True(t, ok)
+		assert.True(t, TuplesEqual(d.Scale, NewVector(1, 1, 1)))
+	})
+
+	t.Run("is the inverse of the view transform", func(t *testing.T) {
+		from, to, up := NewPoint(1, 3, 2), NewPoint(4, -2, 8), NewVector(0, 6, 5)
+
+		assert.True(t, MatricesEqual(LookAt(from, to, up), NewViewTransform(from, to, up).Inverse()))
+	})
+}
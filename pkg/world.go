@@ -3,12 +3,25 @@ package goray
 import (
 	"cmp"
 	"math"
+	"math/rand/v2"
 	"slices"
 )
 
 type World struct {
 	LightSource PointLight
+	AreaLights  []AreaLight
 	Objects     []Shape
+	Background  Background
+	IBLSamples  int
+	Fog         *Fog
+	Volumes     []Volume
+	Rand        *rand.Rand
+	inGlossy    bool
+	time        float64
+	spheres     *sphereBatch
+	// first, when set, catches what the next ray ColorAt or an integrator
+	// traces hits, for AOVs. It is cleared before any rays that ray spawns.
+	first *firstHit
 }
 
 func NewWorld() World {
@@ -17,8 +30,16 @@ func NewWorld() World {
 
 func (w World) Intersect(ray Ray) Intersections {
 	xs := Intersections{}
-	for _, object := range w.Objects {
-		xs = append(xs, ray.Intersect(object)...)
+	if w.spheres.covers(w.Objects) {
+		var words [4]uint64
+		missed := w.spheres.misses(ray, words[:0])
+		for i := range w.Objects {
+			xs = append(xs, w.spheres.intersect(ray, i, missed)...)
+		}
+	} else {
+		for _, object := range w.Objects {
+			xs = append(xs, ray.Intersect(object)...)
+		}
 	}
 	slices.SortFunc(xs, func(a, b Intersection) int {
 		return cmp.Compare(a.T, b.T)
@@ -26,14 +47,51 @@ func (w World) Intersect(ray Ray) Intersections {
 	return xs
 }
 
+// Prepare returns w ready to render many rays, with its spheres batched
+// for faster intersection. Renders prepare the worlds they are given
+// themselves, so this is only worth calling to render the same world more
+// than once. Nothing about w may change while it is in use.
+func (w World) Prepare() World {
+	return w.prepared()
+}
+
+// PrepareChanged prepares w, a new version of previous, which was prepared,
+// keeping what was prepared for the objects diff does not list as changed.
+func (w World) PrepareChanged(previous World, diff SceneDiff) World {
+	if diff.ObjectsReordered || !previous.spheres.covers(previous.Objects) {
+		return w.Prepare()
+	}
+	w.spheres = newSphereBatch(w.Objects, previous.spheres, func(i int) bool {
+		_, changed := slices.BinarySearch(diff.Objects, i)
+		return !changed
+	})
+	return w
+}
+
+// prepared is w as Prepare returns it, or w itself if it already is.
+func (w World) prepared() World {
+	if !w.spheres.covers(w.Objects) {
+		w.spheres = newSphereBatch(w.Objects, nil, nil)
+	}
+	return w
+}
+
 func (w World) ShadeHit(c Computations, depth int) Color {
-	inShadow := w.IsShadowed(c.OverPoint)
-	surface := c.Object.GetMaterial().Lighting(c.Object, w.LightSource, c.Point, c.Eyev, c.Normalv, inShadow)
+	w.time = c.Time
+	material := c.Object.GetMaterial()
+	lightIntensity := w.ShadowTransmittance(c.OverPoint, w.LightSource.Position)
+	surface := material.Lighting(c.Object, w.LightSource, c.Point, c.Eyev, c.Normalv, lightIntensity)
+	surface = surface.Add(material.Emission)
+	for _, light := range w.AreaLights {
+		surface = surface.Add(w.AreaLighting(c, light))
+	}
+	if w.IBLSamples > 0 {
+		surface = surface.Add(w.EnvironmentLighting(c))
+	}
 
 	reflected := w.ReflectedColor(c, depth)
 	refracted := w.RefractedColor(c, depth)
 
-	material := c.Object.GetMaterial()
 	if material.Reflective > 0.0 && material.Transparency > 0.0 {
 		reflectance := c.Schlick()
 		return surface.Add(reflected.Mul(reflectance)).Add(refracted.Mul(1.0 - reflectance))
@@ -43,24 +101,125 @@ func (w World) ShadeHit(c Computations, depth int) Color {
 }
 
 func (w World) ColorAt(r Ray, depth int) Color {
+	w.time = r.Time
 	xs := w.Intersect(r)
 	if hit, isHit := xs.Hit(); isHit {
 		comps := hit.PrepareComputations(r, xs)
-		return w.ShadeHit(comps, depth)
+		w.first.record(r, &hit, comps)
+		w.first = nil
+		return w.applyMedia(r, hit.T, w.ShadeHit(comps, depth))
 	}
-	return NewColor(0, 0, 0)
+	w.first.record(r, nil, Computations{})
+	return w.applyMedia(r, math.Inf(1), w.BackgroundColor(r.Direction))
+}
+
+func (w World) BackgroundColor(direction Vector) Color {
+	if w.Background == nil {
+		return Black()
+	}
+	return w.Background.At(direction)
 }
 
 func (w World) IsShadowed(point Point) bool {
-	v := w.LightSource.Position.Sub(point)
-	distance := v.Magnitude()
-	ray := NewRay(point, v.Normalize())
+	return w.ShadowTransmittance(point, w.LightSource.Position) < 1.0
+}
+
+// ShadowTransmittance returns the fraction of light that reaches point from
+// lightPosition. Each shadow-casting object between the two scales the light
+// by its material's Transparency, once per object regardless of how many of
+// its surfaces the shadow ray crosses.
+func (w World) ShadowTransmittance(point, lightPosition Point) float64 {
+	return w.transmittance(point, lightPosition, nil)
+}
+
+func (w World) transmittance(point, lightPosition Point, ignore Shape) float64 {
+	v := lightPosition.Sub(point)
+	return w.rayTransmittance(NewRayAtTime(point, v.Normalize(), w.time), v.Magnitude(), ignore)
+}
+
+func (w World) rayTransmittance(ray Ray, distance float64, ignore Shape) float64 {
 	xs := w.Intersect(ray)
 
-	if hit, isHit := xs.Hit(); isHit {
-		return hit.T < distance
+	transmittance := 1.0
+	occluders := []Shape{}
+	for _, x := range xs {
+		if x.T < 0.0 {
+			continue
+		}
+		if x.T >= distance {
+			break
+		}
+		material := x.Object.GetMaterial()
+		if !material.CastsShadow || x.Object == ignore || slices.Contains(occluders, x.Object) {
+			continue
+		}
+		occluders = append(occluders, x.Object)
+		transmittance *= material.Transparency
+		if transmittance == 0.0 {
+			return 0.0
+		}
+	}
+	for _, v := range w.Volumes {
+		transmittance *= v.transmittance(ray, distance)
+	}
+	return transmittance
+}
+
+func (w World) AreaLighting(c Computations, light AreaLight) Color {
+	if c.Object == light.Shape || light.Samples < 1 {
+		return Black()
+	}
+
+	material := c.Object.GetMaterial()
+	rng := w.random(Tuple(c.Point))
+	color := Black()
+	for range light.Samples {
+		sample, lightIntensity := w.areaLightSample(c, light, rng)
+		if lightIntensity > 0.0 {
+			color = color.Add(material.directLighting(c.Object, sample, c.Point, c.Eyev, c.Normalv).Mul(lightIntensity))
+		}
+	}
+	return color.Div(float64(light.Samples))
+}
+
+func (w World) isAreaLight(s Shape) bool {
+	return slices.ContainsFunc(w.AreaLights, func(light AreaLight) bool {
+		return light.Shape == s
+	})
+}
+
+// areaLightSample picks a random point on light as a point light, along with
+// how much of its light reaches c: none when c is behind the emitting side of
+// the surface, otherwise the shadow transmittance between them.
+func (w World) areaLightSample(c Computations, light AreaLight, rng *rand.Rand) (PointLight, float64) {
+	position := light.SamplePoint(rng.Float64(), rng.Float64())
+	sample := NewPointLight(position, light.Intensity())
+	if NormalAtTime(light.Shape, position, c.Time).Dot(c.Point.Sub(position)) <= 0.0 {
+		return sample, 0.0
+	}
+	return sample, w.transmittance(c.OverPoint, position, light.Shape)
+}
+
+// EnvironmentLighting is the diffuse light the background casts on a surface,
+// estimated from IBLSamples cosine-weighted directions around its normal.
+func (w World) EnvironmentLighting(c Computations) Color {
+	if w.Background == nil || w.IBLSamples < 1 {
+		return Black()
+	}
+
+	material := c.Object.GetMaterial()
+	albedo := PatternAtObject(material.Pattern, c.Object, c.Point).Mul(material.Diffuse)
+	rng := w.random(Tuple(c.Point))
+
+	irradiance := Black()
+	for range w.IBLSamples {
+		direction := cosineSampleHemisphere(c.Normalv, rng)
+		visibility := w.rayTransmittance(NewRayAtTime(c.OverPoint, direction, c.Time), math.Inf(1), nil)
+		if visibility > 0.0 {
+			irradiance = irradiance.Add(w.Background.At(direction).Mul(visibility))
+		}
 	}
-	return false
+	return albedo.Prod(irradiance.Div(float64(w.IBLSamples)))
 }
 
 func (w World) ReflectedColor(c Computations, depth int) Color {
@@ -70,10 +229,16 @@ func (w World) ReflectedColor(c Computations, depth int) Color {
 	if c.Object.GetMaterial().Reflective == 0 {
 		return Black()
 	}
-	reflectRay := NewRay(c.OverPoint, c.Reflectv)
-	color := w.ColorAt(reflectRay, depth-1)
+	material := c.Object.GetMaterial()
+	if material.Roughness == 0.0 {
+		reflectRay := NewRayAtTime(c.OverPoint, c.Reflectv, c.Time)
+		return w.ColorAt(reflectRay, depth-1).Mul(material.Reflective)
+	}
 
-	return color.Mul(c.Object.GetMaterial().Reflective)
+	color := w.glossyColor(c, c.Reflectv, material, depth, func(direction Vector) (Ray, bool) {
+		return NewRayAtTime(c.OverPoint, direction, c.Time), direction.Dot(c.Normalv) > 0.0
+	})
+	return color.Mul(material.Reflective)
 }
 
 func (w World) RefractedColor(c Computations, depth int) Color {
@@ -83,18 +248,58 @@ func (w World) RefractedColor(c Computations, depth int) Color {
 	if c.Object.GetMaterial().Transparency == 0.0 {
 		return Black()
 	}
-	nRatio := c.N1 / c.N2
-	cosI := c.Eyev.Dot(c.Normalv)
+	direction, ok := c.RefractedDirection()
+	if !ok {
+		return Black()
+	}
 
-	sin2T := nRatio * nRatio * (1 - cosI*cosI)
+	material := c.Object.GetMaterial()
+	if material.Roughness == 0.0 {
+		refractRay := NewRayAtTime(c.UnderPoint, direction, c.Time)
+		return w.ColorAt(refractRay, depth-1).Mul(material.Transparency)
+	}
 
-	if sin2T > 1.0 {
-		return Black()
+	color := w.glossyColor(c, direction, material, depth, func(d Vector) (Ray, bool) {
+		return NewRayAtTime(c.UnderPoint, d, c.Time), d.Dot(c.Normalv) < 0.0
+	})
+	return color.Mul(material.Transparency)
+}
+
+// glossyColor averages rays sampled in a lobe around axis whose width grows
+// with the material's Roughness. rayFor reports false for directions on the
+// wrong side of the surface; those samples are absorbed and count as black.
+// Only the outermost glossy bounce takes GlossySamples rays; glossy surfaces
+// seen through it take one each, which keeps the ray count from growing
+// exponentially with depth.
+func (w World) glossyColor(c Computations, axis Vector, material Material, depth int, rayFor func(Vector) (Ray, bool)) Color {
+	samples := material.GlossySamples
+	if w.inGlossy || samples < 1 {
+		samples = 1
 	}
+	rng := w.random(Tuple(c.Point), Tuple(axis))
+	w.inGlossy = true
 
-	cosT := math.Sqrt(1.0 - sin2T)
-	direction := c.Normalv.Mul(nRatio*cosI - cosT).Sub(c.Eyev.Mul(nRatio))
+	color := Black()
+	for range samples {
+		if ray, ok := rayFor(sampleLobe(axis, material.Roughness, rng)); ok {
+			color = color.Add(w.ColorAt(ray, depth-1))
+		}
+	}
+	return color.Div(float64(samples))
+}
 
-	refractRay := NewRay(c.UnderPoint, direction)
-	return w.ColorAt(refractRay, depth-1).Mul(c.Object.GetMaterial().Transparency)
+// random returns w's Rand, or when it has none, as when a world is shaded
+// outside a render, a generator seeded from keys. Seeding from where the
+// sample is taken rather than sharing one generator keeps it safe to use from
+// many goroutines, and the result the same whatever was shaded first.
+func (w World) random(keys ...Tuple) *rand.Rand {
+	if w.Rand != nil {
+		return w.Rand
+	}
+	var seed1, seed2 uint64
+	for _, key := range keys {
+		seed1 = seed1*0x9e3779b97f4a7c15 ^ math.Float64bits(key.x) ^ math.Float64bits(key.z)<<1
+		seed2 = seed2*0x9e3779b97f4a7c15 ^ math.Float64bits(key.y)
+	}
+	return rand.New(rand.NewPCG(seed1, seed2))
 }
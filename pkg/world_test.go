@@ -3,6 +3,7 @@ package goray
 import (
 	"fmt"
 	"math"
+	"math/rand/v2"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -127,7 +128,7 @@ func TestShadeHit(t *testing.T) {
 
 		comps := xs[0].PrepareComputations(r, xs)
 		c := w.ShadeHit(comps, 5)
-		assert.True(t, TuplesEqual(c, NewColor(0.93642, 0.68642, 0.68642)))
+		assert.True(t, TuplesEqual(c, NewColor(1.12547, 0.68643, 0.68643)))
 	})
 
 	t.Run("with a reflective, transparent material", func(t *testing.T) {
@@ -153,7 +154,69 @@ func TestShadeHit(t *testing.T) {
 
 		comps := xs[0].PrepareComputations(r, xs)
 		c := w.ShadeHit(comps, 5)
-		assert.True(t, TuplesEqual(c, NewColor(0.93391, 0.69643, 0.69243)))
+		assert.True(t, TuplesEqual(c, NewColor(1.11500, 0.69643, 0.69243)))
+	})
+}
+
+func TestShadeHitWithEmission(t *testing.T) {
+	w := defaultWorld()
+	m := w.Objects[0].GetMaterial()
+	m.Emission = NewColor(0.5, 0.5, 0.5)
+	w.Objects[0].SetMaterial(m)
+
+	r := NewRay(NewPoint(0, 0, -5), NewVector(0, 0, 1))
+	i := NewIntersection(4, w.Objects[0])
+	comps := i.PrepareComputations(r, Intersections{i})
+
+	c := w.ShadeHit(comps, 0)
+
+	assert.True(t, TuplesEqual(c, NewColor(0.88066, 0.97583, 0.7855)))
+}
+
+func TestAreaLighting(t *testing.T) {
+	areaLightWorld := func() (World, *Rectangle, Computations) {
+		w := NewWorld()
+		w.Rand = rand.New(rand.NewPCG(0, 0))
+		panel := NewRectangle()
+		panel.SetTransform(Translation(0, 4, 0).Mul(RotationX(math.Pi)))
+		panel.Material.Emission = White()
+		floor := NewPlane()
+		w.Objects = []Shape{&floor, &panel}
+		w.AreaLights = []AreaLight{NewAreaLight(&panel, 16)}
+
+		r := NewRay(NewPoint(0, 1, -1), NewVector(0, -1, 1).Normalize())
+		i := NewIntersection(math.Sqrt2, &floor)
+		return w, &panel, i.PrepareComputations(r, Intersections{i})
+	}
+
+	t.Run("lights surfaces in front of the emitter", func(t *testing.T) {
+		w, _, comps := areaLightWorld()
+		c := w.AreaLighting(comps, w.AreaLights[0])
+		assert.Greater(t, c.x, 0.5)
+	})
+
+	t.Run("does not light surfaces behind the emitter", func(t *testing.T) {
+		w, panel, comps := areaLightWorld()
+		panel.SetTransform(Translation(0, 4, 0))
+		c := w.AreaLighting(comps, w.AreaLights[0])
+		assert.Equal(t, c, Black())
+	})
+
+	t.Run("is blocked by occluders", func(t *testing.T) {
+		w, _, comps := areaLightWorld()
+		blocker := NewRectangle()
+		blocker.SetTransform(Translation(0, 2, 0).Mul(Scaling(10, 1, 10)))
+		w.Objects = append(w.Objects, &blocker)
+		c := w.AreaLighting(comps, w.AreaLights[0])
+		assert.Equal(t, c, Black())
+	})
+
+	t.Run("does not light the emitter itself", func(t *testing.T) {
+		w, panel, _ := areaLightWorld()
+		r := NewRay(NewPoint(0, 0, 0), NewVector(0, 1, 0))
+		i := NewIntersection(4, panel)
+		comps := i.PrepareComputations(r, Intersections{i})
+		assert.Equal(t, w.AreaLighting(comps, w.AreaLights[0]), Black())
 	})
 }
 
@@ -205,6 +268,59 @@ func TestIsShadowed(t *testing.T) {
 
 }
 
+func TestShadowTransmittance(t *testing.T) {
+	light := NewPoint(0, 0, -10)
+	point := NewPoint(0, 0, 10)
+
+	t.Run("with nothing between the point and the light", func(t *testing.T) {
+		w := NewWorld()
+		assert.Equal(t, w.ShadowTransmittance(point, light), 1.0)
+	})
+
+	t.Run("through an opaque object", func(t *testing.T) {
+		w := NewWorld()
+		s := NewSphere()
+		w.Objects = []Shape{&s}
+		assert.Equal(t, w.ShadowTransmittance(point, light), 0.0)
+	})
+
+	t.Run("through a transparent object", func(t *testing.T) {
+		w := NewWorld()
+		s := GlassSphere()
+		s.Material.Transparency = 0.8
+		w.Objects = []Shape{&s}
+		assert.InDelta(t, w.ShadowTransmittance(point, light), 0.8, 0.00001)
+	})
+
+	t.Run("through several transparent objects", func(t *testing.T) {
+		w := NewWorld()
+		s1 := GlassSphere()
+		s1.Material.Transparency = 0.8
+		s2 := GlassSphere()
+		s2.Material.Transparency = 0.5
+		s2.SetTransform(Translation(0, 0, 3))
+		w.Objects = []Shape{&s1, &s2}
+		assert.InDelta(t, w.ShadowTransmittance(point, light), 0.4, 0.00001)
+	})
+
+	t.Run("through an object that does not cast shadows", func(t *testing.T) {
+		w := NewWorld()
+		s := NewSphere()
+		s.Material.CastsShadow = false
+		w.Objects = []Shape{&s}
+		assert.Equal(t, w.ShadowTransmittance(point, light), 1.0)
+		assert.False(t, w.IsShadowed(point))
+	})
+
+	t.Run("with the object beyond the light", func(t *testing.T) {
+		w := NewWorld()
+		s := NewSphere()
+		s.SetTransform(Translation(0, 0, -20))
+		w.Objects = []Shape{&s}
+		assert.Equal(t, w.ShadowTransmittance(point, light), 1.0)
+	})
+}
+
 func TestReflectedColor(t *testing.T) {
 	t.Run("for a nonreflective material", func(t *testing.T) {
 		w := defaultWorld()
@@ -256,6 +372,81 @@ func TestReflectedColor(t *testing.T) {
 	})
 }
 
+func TestGlossyReflectedColor(t *testing.T) {
+	glossyWorld := func(seed uint64) (World, Computations) {
+		w := defaultWorld()
+		w.Rand = rand.New(rand.NewPCG(seed, 0))
+		s := NewPlane()
+		s.Material.Reflective = 0.5
+		s.Material.Roughness = 0.3
+		s.SetTransform(Translation(0, -1, 0))
+		w.Objects = append(w.Objects, &s)
+
+		r := NewRay(NewPoint(0, 0, -3), NewVector(0, -math.Sqrt2/2, math.Sqrt2/2))
+		i := NewIntersection(math.Sqrt2, &s)
+
+		return w, i.PrepareComputations(r, Intersections{i})
+	}
+
+	t.Run("differs from a perfect reflection", func(t *testing.T) {
+		w, comps := glossyWorld(1)
+		c := w.ReflectedColor(comps, 1)
+		assert.False(t, TuplesEqual(c, NewColor(0.19033, 0.23791, 0.14274)))
+	})
+
+	t.Run("is repeatable with the same seed", func(t *testing.T) {
+		w1, comps1 := glossyWorld(1)
+		w2, comps2 := glossyWorld(1)
+		assert.Equal(t, w1.ReflectedColor(comps1, 1), w2.ReflectedColor(comps2, 1))
+	})
+
+	t.Run("without a Rand does not depend on what was shaded before", func(t *testing.T) {
+		w, comps := glossyWorld(1)
+		w.Rand = nil
+		first := w.ReflectedColor(comps, 1)
+		assert.Equal(t, w.ReflectedColor(comps, 1), first)
+	})
+
+	t.Run("without a Rand differs between points with the same reflection", func(t *testing.T) {
+		w, comps := glossyWorld(1)
+		w.Rand = nil
+		moved := comps
+		moved.Point = moved.Point.Add(NewVector(0.5, 0, 0))
+
+		sampled := func(c Computations) Vector {
+			var direction Vector
+			w.glossyColor(c, c.Reflectv, c.Object.GetMaterial(), 1, func(d Vector) (Ray, bool) {
+				direction = d
+				return Ray{}, false
+			})
+			return direction
+		}
+		assert.NotEqual(t, sampled(moved), sampled(comps))
+	})
+
+	t.Run("absorbs samples that fall below the surface", func(t *testing.T) {
+		// The widest lobe covers the hemisphere around the reflection, so a
+		// white sky is reflected in full looking straight down, but only half
+		// of it is reflected at a grazing angle.
+		w := NewWorld()
+		w.LightSource = NewPointLight(NewPoint(0, 10, 0), Black())
+		w.Background = NewConstantBackground(White())
+		s := NewPlane()
+		s.Material.Reflective = 1.0
+		s.Material.Roughness = 1.0
+		s.Material.GlossySamples = 4000
+		w.Objects = []Shape{&s}
+
+		reflected := func(direction Vector) float64 {
+			r := NewRay(NewPoint(0, 0, 0).Add(direction.Neg()), direction)
+			i := NewIntersection(1, &s)
+			return w.ReflectedColor(i.PrepareComputations(r, Intersections{i}), 1).x
+		}
+		assert.InDelta(t, reflected(NewVector(0, -1, 0)), 1.0, 0.0001)
+		assert.InDelta(t, reflected(NewVector(0, -0.001, 1).Normalize()), 0.5, 0.03)
+	})
+}
+
 func TestRefractedColor(t *testing.T) {
 	t.Run("with an opaque surface", func(t *testing.T) {
 		w := defaultWorld()
@@ -345,6 +536,36 @@ func TestRefractedColor(t *testing.T) {
 		fmt.Println(c)
 		assert.True(t, TuplesEqual(c, NewColor(0, 0.99888, 0.04722)))
 	})
+
+	t.Run("with a rough refracted ray", func(t *testing.T) {
+		w := defaultWorld()
+		w.Rand = rand.New(rand.NewPCG(1, 0))
+		m := w.Objects[0].GetMaterial()
+		m.Ambient = 1.0
+		pattern := DemoPattern{Transform: IdentityMatrix()}
+		m.Pattern = &pattern
+		w.Objects[0].SetMaterial(m)
+
+		m = w.Objects[1].GetMaterial()
+		m.Transparency = 1.0
+		m.RefractiveIndex = 1.5
+		m.Roughness = 0.5
+		w.Objects[1].SetMaterial(m)
+
+		r := NewRay(NewPoint(0, 0, 0.1), NewVector(0, 1, 0))
+
+		xs := Intersections{
+			NewIntersection(-0.9899, w.Objects[0]),
+			NewIntersection(-0.4899, w.Objects[1]),
+			NewIntersection(0.4899, w.Objects[1]),
+			NewIntersection(0.9899, w.Objects[0]),
+		}
+
+		comps := xs[2].PrepareComputations(r, xs)
+		c := w.RefractedColor(comps, 5)
+		assert.False(t, TuplesEqual(c, NewColor(0, 0.99888, 0.04722)))
+		assert.Greater(t, c.y, 0.0)
+	})
 }
 
 type DemoPattern struct {
@@ -356,8 +577,8 @@ func (dp *DemoPattern) At(p Point) Color {
 }
 
 func (dp *DemoPattern) AtObject(shape Shape, point Point) Color {
-	objectPoint := shape.GetTransform().Inverse().Mult(point)
-	patternPoint := dp.GetTransform().Inverse().Mult(objectPoint)
+	objectPoint := shape.GetTransform().Inverse().MulPoint(point)
+	patternPoint := dp.GetTransform().Inverse().MulPoint(objectPoint)
 	return dp.At(patternPoint)
 }
 
@@ -368,3 +589,58 @@ func (dp *DemoPattern) GetTransform() Matrix {
 func (dp *DemoPattern) SetTransform(m Matrix) {
 	dp.Transform = m
 }
+
+// sphereField is a floor under a grid of spheres, for benchmarking scenes
+// with many objects.
+func sphereField(n int) World {
+	floor := NewPlane()
+	w := World{
+		LightSource: NewPointLight(NewPoint(-10, 10, -10), NewColor(1, 1, 1)),
+		Objects:     []Shape{&floor},
+	}
+	for i := range n {
+		for j := range n {
+			s := NewSphere()
+			s.SetTransform(NewTransformBuilder().Scale(0.4, 0.4, 0.4).Translate(float64(i-n/2), 0.4, float64(j)).Matrix())
+			w.Objects = append(w.Objects, &s)
+		}
+	}
+	return w
+}
+
+// fieldRays are the camera rays of a small image of sphereField.
+func fieldRays() []Ray {
+	c := NewCamera(32, 1, math.Pi/3)
+	c.Transform = NewViewTransform(NewPoint(0, 3, -6), NewPoint(0, 0, 4), NewVector(0, 1, 0))
+	rays := make([]Ray, 0, c.Width*c.Height)
+	for y := range c.Height {
+		for x := range c.Width {
+			rays = append(rays, c.RayForPixel(x, y))
+		}
+	}
+	return rays
+}
+
+func BenchmarkWorldIntersect(b *testing.B) {
+	w := sphereField(10)
+	rays := fieldRays()
+	worlds := map[string]World{"one at a time": w, "batched": w.prepared()}
+
+	for description, w := range worlds {
+		b.Run(description, func(b *testing.B) {
+			for i := range b.N {
+				w.Intersect(rays[i%len(rays)])
+			}
+		})
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	w := sphereField(10)
+	c := NewCamera(32, 1, math.Pi/3)
+	c.Transform = NewViewTransform(NewPoint(0, 3, -6), NewPoint(0, 0, 4), NewVector(0, 1, 0))
+
+	for range b.N {
+		c.RenderTile(w, 0, 0, c.Width, c.Height)
+	}
+}